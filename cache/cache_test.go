@@ -0,0 +1,204 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/core"
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testImage = types.Image{Registry: "docker.io", Namespace: "library", Name: "nginx", Tag: "1.25"}
+
+// fakeLister counts the tag listings requested by the cache
+type fakeLister struct {
+	tags  []string
+	calls int
+	err   error
+}
+
+func (f *fakeLister) GetTags(context.Context, types.Image) ([]string, error) {
+	f.calls++
+	return f.tags, f.err
+}
+
+// fakeConditionalLister revalidates listings with a fixed ETag
+type fakeConditionalLister struct {
+	fakeLister
+	etag        string
+	revalidated int
+}
+
+func (f *fakeConditionalLister) GetTagsIfChanged(ctx context.Context, image types.Image, etag string) ([]string, string, bool, error) {
+	if etag != "" && etag == f.etag {
+		f.revalidated++
+		return nil, etag, false, nil
+	}
+	tags, err := f.GetTags(ctx, image)
+	return tags, f.etag, true, err
+}
+
+func newTestClient(t *testing.T, next core.RegistryClient, ttl time.Duration, refresh bool) (*Client, *time.Time) {
+	t.Helper()
+
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	client := NewClient(next, NewStore(t.TempDir()), ttl, refresh, zap.NewNop().Sugar())
+	client.now = func() time.Time { return now }
+	return client, &now
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "docker.io/library/nginx", Key(testImage))
+}
+
+func TestStore_PutGet(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "nested"))
+
+	_, found, err := store.Get("docker.io/library/nginx")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	fetchedAt := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, store.Put("docker.io/library/nginx", Entry{Tags: []string{"1.25", "1.27"}, ETag: `"abc"`, FetchedAt: fetchedAt}))
+
+	entry, found, err := store.Get("docker.io/library/nginx")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, Entry{Key: "docker.io/library/nginx", Tags: []string{"1.25", "1.27"}, ETag: `"abc"`, FetchedAt: fetchedAt}, entry)
+
+	_, found, err = store.Get("docker.io/library/redis")
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestStore_CorruptedEntry(t *testing.T) {
+	store := NewStore(t.TempDir())
+	require.NoError(t, os.WriteFile(store.path("key"), []byte("{"), 0o644))
+
+	_, _, err := store.Get("key")
+	assert.Error(t, err)
+}
+
+func TestDefaultDir(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/tmp/xdg-cache")
+	dir, err := DefaultDir()
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/xdg-cache/chuck", dir)
+}
+
+func TestClient_TTL(t *testing.T) {
+	lister := &fakeLister{tags: []string{"1.25"}}
+	client, now := newTestClient(t, lister, time.Hour, false)
+
+	for range 3 {
+		tags, err := client.GetTags(context.Background(), testImage)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"1.25"}, tags)
+	}
+	assert.Equal(t, 1, lister.calls)
+
+	*now = now.Add(2 * time.Hour)
+	_, err := client.GetTags(context.Background(), testImage)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, lister.calls)
+}
+
+func TestClient_Refresh(t *testing.T) {
+	lister := &fakeLister{tags: []string{"1.25"}}
+	client, _ := newTestClient(t, lister, time.Hour, true)
+
+	for range 2 {
+		_, err := client.GetTags(context.Background(), testImage)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, lister.calls)
+
+	// Refreshed results are still saved for the following runs
+	_, found, err := client.store.Get(Key(testImage))
+	assert.NoError(t, err)
+	assert.True(t, found)
+}
+
+func TestClient_Revalidation(t *testing.T) {
+	lister := &fakeConditionalLister{fakeLister: fakeLister{tags: []string{"1.25"}}, etag: `"v1"`}
+	client, now := newTestClient(t, lister, time.Hour, false)
+
+	_, err := client.GetTags(context.Background(), testImage)
+	assert.NoError(t, err)
+
+	*now = now.Add(2 * time.Hour)
+	tags, err := client.GetTags(context.Background(), testImage)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.25"}, tags)
+	assert.Equal(t, 1, lister.calls)
+	assert.Equal(t, 1, lister.revalidated)
+
+	// Revalidation renews the entry
+	_, err = client.GetTags(context.Background(), testImage)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, lister.revalidated)
+
+	// A changed listing is fetched again
+	lister.etag = `"v2"`
+	lister.tags = []string{"1.25", "1.27"}
+	*now = now.Add(2 * time.Hour)
+	tags, err = client.GetTags(context.Background(), testImage)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.25", "1.27"}, tags)
+	assert.Equal(t, 2, lister.calls)
+}
+
+func TestClient_Errors(t *testing.T) {
+	lister := &fakeLister{err: errors.New("registry down")}
+	client, _ := newTestClient(t, lister, time.Hour, false)
+
+	_, err := client.GetTags(context.Background(), testImage)
+	assert.EqualError(t, err, "registry down")
+
+	// Failures are not cached
+	_, found, _ := client.store.Get(Key(testImage))
+	assert.False(t, found)
+}
+
+// fakeInfoLister returns tags along with their metadata
+type fakeInfoLister struct {
+	fakeLister
+	infos []types.TagInfo
+}
+
+func (f *fakeInfoLister) GetTagInfos(context.Context, types.Image) ([]types.TagInfo, error) {
+	f.calls++
+	return f.infos, f.err
+}
+
+func TestClient_TagInfos(t *testing.T) {
+	modified := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	lister := &fakeInfoLister{infos: []types.TagInfo{{Name: "1.25", LastModified: modified, Digest: "sha256:abc"}}}
+	client, now := newTestClient(t, lister, time.Hour, false)
+
+	for range 2 {
+		infos, err := client.GetTagInfos(context.Background(), testImage)
+		assert.NoError(t, err)
+		require.Len(t, infos, 1)
+		assert.True(t, modified.Equal(infos[0].LastModified))
+	}
+	assert.Equal(t, 1, lister.calls)
+
+	*now = now.Add(2 * time.Hour)
+	_, err := client.GetTagInfos(context.Background(), testImage)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, lister.calls)
+
+	// Clients without metadata only return the tag names
+	plain, _ := newTestClient(t, &fakeLister{tags: []string{"1.25"}}, time.Hour, false)
+	infos, err := plain.GetTagInfos(context.Background(), testImage)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.TagInfo{{Name: "1.25"}}, infos)
+}
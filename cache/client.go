@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/core"
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"go.uber.org/zap"
+)
+
+// DefaultTTL is the default time a cached tag listing is used without contacting the registry
+const DefaultTTL = 6 * time.Hour
+
+// ConditionalTagLister is implemented by registry clients able to revalidate
+// a previous listing with its ETag (If-None-Match)
+type ConditionalTagLister interface {
+	// GetTagsIfChanged returns modified=false when the listing identified by etag is still current,
+	// otherwise the new tags along with their ETag
+	GetTagsIfChanged(ctx context.Context, image types.Image, etag string) (tags []string, newETag string, modified bool, err error)
+}
+
+// Client wraps a registry client, serving tag listings from the persistent cache
+type Client struct {
+	next    core.RegistryClient
+	store   *Store
+	ttl     time.Duration
+	refresh bool
+	logger  *zap.SugaredLogger
+	now     func() time.Time
+}
+
+// NewClient creates a Client caching the listings of next in store for ttl.
+// When refresh is set cached listings are ignored, but fresh results are still saved.
+func NewClient(next core.RegistryClient, store *Store, ttl time.Duration, refresh bool, logger *zap.SugaredLogger) *Client {
+	return &Client{
+		next:    next,
+		store:   store,
+		ttl:     ttl,
+		refresh: refresh,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// GetTags returns the cached tags of image while fresh, revalidates them with the
+// registry when supported, and fetches them otherwise.
+// Cache failures are logged and never prevent fetching tags from the registry.
+func (c *Client) GetTags(ctx context.Context, image types.Image) ([]string, error) {
+	key := Key(image)
+
+	entry, found, err := c.store.Get(key)
+	if err != nil {
+		c.logger.Warnf("ignoring tag cache for %s: %v", key, err)
+		found = false
+	}
+
+	if found && !c.refresh {
+		if c.now().Sub(entry.FetchedAt) < c.ttl {
+			c.logger.Debugf("using cached tags for %s (fetched at %s)", key, entry.FetchedAt.Format(time.RFC3339))
+			return entry.Tags, nil
+		}
+
+		if conditional, ok := c.next.(ConditionalTagLister); ok && entry.ETag != "" {
+			tags, etag, modified, err := conditional.GetTagsIfChanged(ctx, image, entry.ETag)
+			if err != nil {
+				return nil, err
+			}
+			if !modified {
+				c.logger.Debugf("cached tags for %s are still current", key)
+				entry.FetchedAt = c.now()
+				c.save(key, entry)
+				return entry.Tags, nil
+			}
+
+			c.save(key, Entry{Tags: tags, ETag: etag, FetchedAt: c.now()})
+			return tags, nil
+		}
+	}
+
+	if conditional, ok := c.next.(ConditionalTagLister); ok {
+		tags, etag, _, err := conditional.GetTagsIfChanged(ctx, image, "")
+		if err != nil {
+			return nil, err
+		}
+		c.save(key, Entry{Tags: tags, ETag: etag, FetchedAt: c.now()})
+		return tags, nil
+	}
+
+	tags, err := c.next.GetTags(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+	c.save(key, Entry{Tags: tags, FetchedAt: c.now()})
+	return tags, nil
+}
+
+// GetTagInfos returns the cached tags of image along with their metadata while fresh,
+// and fetches them otherwise. Tags of registry clients not returning metadata only have a name.
+func (c *Client) GetTagInfos(ctx context.Context, image types.Image) ([]types.TagInfo, error) {
+	lister, ok := c.next.(core.TagInfoLister)
+	if !ok {
+		tags, err := c.GetTags(ctx, image)
+		if err != nil {
+			return nil, err
+		}
+		infos := make([]types.TagInfo, len(tags))
+		for i, tag := range tags {
+			infos[i] = types.TagInfo{Name: tag}
+		}
+		return infos, nil
+	}
+
+	key := Key(image)
+
+	entry, found, err := c.store.Get(key)
+	if err != nil {
+		c.logger.Warnf("ignoring tag cache for %s: %v", key, err)
+		found = false
+	}
+
+	if found && !c.refresh && entry.Infos != nil && c.now().Sub(entry.FetchedAt) < c.ttl {
+		c.logger.Debugf("using cached tags for %s (fetched at %s)", key, entry.FetchedAt.Format(time.RFC3339))
+		return entry.Infos, nil
+	}
+
+	infos, err := lister.GetTagInfos(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]string, len(infos))
+	for i, info := range infos {
+		tags[i] = info.Name
+	}
+	c.save(key, Entry{Tags: tags, Infos: infos, FetchedAt: c.now()})
+	return infos, nil
+}
+
+// save stores entry, logging failures
+func (c *Client) save(key string, entry Entry) {
+	if err := c.store.Put(key, entry); err != nil {
+		c.logger.Warnf("failed to update tag cache: %v", err)
+	}
+}
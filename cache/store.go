@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/types"
+)
+
+// Entry is the cached tag listing of a repository
+type Entry struct {
+	Key       string          `json:"key"`
+	Tags      []string        `json:"tags"`
+	Infos     []types.TagInfo `json:"infos,omitempty"` // Tag metadata, for registries returning it
+	ETag      string          `json:"etag,omitempty"`
+	FetchedAt time.Time       `json:"fetchedAt"`
+}
+
+// Store persists tag listings on disk, one JSON file per repository
+type Store struct {
+	dir string
+}
+
+// DefaultDir returns the cache directory of Chuck, following the XDG Base Directory
+// Specification ($XDG_CACHE_HOME/chuck, defaulting to ~/.cache/chuck)
+func DefaultDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "chuck"), nil
+}
+
+// NewStore creates a Store saving entries in dir
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Key returns the cache key of the repository of an image (e.g. docker.io/library/nginx)
+func Key(image types.Image) string {
+	return fmt.Sprintf("%s/%s/%s", image.Registry, image.Namespace, image.Name)
+}
+
+// Get returns the entry stored for key, if any
+func (s *Store) Get(key string) (Entry, bool, error) {
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Entry{}, false, nil
+	}
+	if err != nil {
+		return Entry{}, false, fmt.Errorf("failed to read cache entry for %s: %w", key, err)
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return Entry{}, false, fmt.Errorf("failed to decode cache entry for %s: %w", key, err)
+	}
+
+	// Guard against hash collisions or files copied around
+	if entry.Key != key {
+		return Entry{}, false, nil
+	}
+
+	return entry, true, nil
+}
+
+// Put saves entry under key, replacing the file atomically
+func (s *Store) Put(key string, entry Entry) error {
+	entry.Key = key
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry for %s: %w", key, err)
+	}
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory %s: %w", s.dir, err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".entry-*")
+	if err != nil {
+		return fmt.Errorf("failed to create cache entry for %s: %w", key, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write cache entry for %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache entry for %s: %w", key, err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path(key)); err != nil {
+		return fmt.Errorf("failed to save cache entry for %s: %w", key, err)
+	}
+	return nil
+}
+
+// path returns the file holding the entry of key
+func (s *Store) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
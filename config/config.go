@@ -0,0 +1,206 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/cache"
+	"github.com/FedericoAntoniazzi/chuck/core"
+	"github.com/FedericoAntoniazzi/chuck/registry/dockerhub"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the configuration file
+const FileName = "chuck.yaml"
+
+// systemConfigDir is the system-wide configuration directory
+var systemConfigDir = "/etc/chuck"
+
+// Config holds every setting of Chuck
+type Config struct {
+	Log                 LogConfig            `yaml:"log"`
+	Output              OutputConfig         `yaml:"output"`
+	Cache               CacheConfig          `yaml:"cache"`
+	Parallelism         int                  `yaml:"parallelism"`
+	RegistryParallelism int                  `yaml:"registryParallelism"`
+	DockerConfig        string               `yaml:"dockerConfig"`
+	DockerHubMaxPages   int                  `yaml:"dockerHubMaxPages"`
+	OCIFallback         bool                 `yaml:"ociFallback"` // Query registries without a dedicated client through the Distribution v2 API
+	DBPath              string               `yaml:"dbPath"`
+	Registries          []RegistryConfig     `yaml:"registries"`
+	Filters             FiltersConfig        `yaml:"filters"`
+	Versions            VersionsConfig       `yaml:"versions"`
+	Notifications       []NotificationConfig `yaml:"notifications"`
+}
+
+// LogConfig configures the logger
+type LogConfig struct {
+	Level  string `yaml:"level"`  // debug, info, warn, error
+	Format string `yaml:"format"` // text, json
+}
+
+// OutputConfig configures the report
+type OutputConfig struct {
+	Format string `yaml:"format"` // text, tab, json, yaml, csv
+	File   string `yaml:"file"`   // Write the report to this file instead of stdout
+	All    bool   `yaml:"all"`    // Report every container in text and tab output
+}
+
+// CacheConfig configures the persistent tag cache
+type CacheConfig struct {
+	Disabled bool          `yaml:"disabled"`
+	TTL      time.Duration `yaml:"ttl"`
+	Dir      string        `yaml:"dir"`
+}
+
+// RegistryConfig configures the access to a registry.
+// Credential values support environment variable references (e.g. ${GHCR_TOKEN}),
+// and $$ stands for a literal $ (e.g. robot$$project+ci).
+type RegistryConfig struct {
+	Host         string `yaml:"host"`
+	Type         string `yaml:"type"`         // oci (default), harbor, nexus, artifactory, gitlab
+	Endpoint     string `yaml:"endpoint"`     // URL of the registry API, when it is not https://<host>
+	AuthEndpoint string `yaml:"authEndpoint"` // URL of the GitLab JWT endpoint (e.g. https://gitlab.example.com/jwt/auth)
+	Repository   string `yaml:"repository"`   // Nexus repository name or Artifactory repository key
+	Username     string `yaml:"username"`
+	Password     string `yaml:"password"`
+	Token        string `yaml:"token"`   // Bearer token sent as-is, or GitLab CI job token
+	APIKey       string `yaml:"apiKey"`  // Artifactory API key
+	KeyFile      string `yaml:"keyFile"` // Path of a Google service account JSON key
+}
+
+// FiltersConfig selects the containers to check
+type FiltersConfig struct {
+	Include     []string `yaml:"include"`
+	Exclude     []string `yaml:"exclude"`
+	ShowSkipped bool     `yaml:"showSkipped"`
+}
+
+// VersionsConfig controls which tags are suggested as updates.
+// Containers can override it with labels (e.g. chuck.policy, chuck.prerelease).
+type VersionsConfig struct {
+	Policy         string   `yaml:"policy"`         // patch, minor, any (major is an alias of any)
+	Prerelease     string   `yaml:"prerelease"`     // exclude, auto, include
+	IgnoreMetadata []string `yaml:"ignoreMetadata"` // Patterns of build metadata ignored in variant suffixes (e.g. -r[0-9]+$)
+}
+
+// NotificationConfig describes a notification target
+type NotificationConfig struct {
+	Type   string `yaml:"type"` // telegram, webhook
+	Token  string `yaml:"token"`
+	ChatID string `yaml:"chatId"`
+	URL    string `yaml:"url"`
+}
+
+// Default returns the configuration used when no file nor override is set
+func Default() *Config {
+	return &Config{
+		Log: LogConfig{
+			Level:  "warn",
+			Format: "text",
+		},
+		Output: OutputConfig{
+			Format: "text",
+		},
+		Cache: CacheConfig{
+			TTL: cache.DefaultTTL,
+		},
+		Parallelism:         core.DefaultParallelism,
+		RegistryParallelism: core.DefaultRegistryParallelism,
+		DockerHubMaxPages:   dockerhub.DefaultMaxPages,
+		OCIFallback:         true,
+		DBPath:              "chuck.db",
+		Versions: VersionsConfig{
+			Policy:     string(core.PolicyAny),
+			Prerelease: string(core.PrereleaseExclude),
+		},
+	}
+}
+
+// SearchPaths returns the locations where the configuration file is looked up, by priority:
+// $XDG_CONFIG_HOME/chuck/chuck.yaml (defaulting to ~/.config) then /etc/chuck/chuck.yaml
+func SearchPaths() []string {
+	var paths []string
+	if userConfigDir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(userConfigDir, "chuck", FileName))
+	}
+	return append(paths, filepath.Join(systemConfigDir, FileName))
+}
+
+// Load reads the configuration from path, or from the first file found in SearchPaths
+// when path is empty, and applies the environment variable overrides.
+// It returns the path of the file read, empty when none was found.
+// The result is not validated, to allow further overrides before calling Validate.
+func Load(path string) (*Config, string, error) {
+	config := Default()
+
+	if path == "" {
+		for _, candidate := range SearchPaths() {
+			if _, err := os.Stat(candidate); err == nil {
+				path = candidate
+				break
+			}
+		}
+	}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil, "", fmt.Errorf("config file %s not found", path)
+			}
+			return nil, "", fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+
+		if err := decode(data, config); err != nil {
+			return nil, "", fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+	}
+
+	if err := applyEnv(config, os.LookupEnv); err != nil {
+		return nil, "", err
+	}
+
+	config.expandCredentials()
+
+	return config, path, nil
+}
+
+// decode parses a YAML document over config, rejecting unknown keys
+func decode(data []byte, config *Config) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// expandCredentials resolves the environment variables referenced by registry credentials
+func (c *Config) expandCredentials() {
+	for i := range c.Registries {
+		c.Registries[i].Username = expandEnv(c.Registries[i].Username)
+		c.Registries[i].Password = expandEnv(c.Registries[i].Password)
+		c.Registries[i].Token = expandEnv(c.Registries[i].Token)
+		c.Registries[i].APIKey = expandEnv(c.Registries[i].APIKey)
+		c.Registries[i].KeyFile = expandEnv(c.Registries[i].KeyFile)
+	}
+}
+
+// expandEnv replaces environment variable references in value like os.ExpandEnv,
+// except for $$ which is replaced by a single $
+func expandEnv(value string) string {
+	return os.Expand(value, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		return os.Getenv(name)
+	})
+}
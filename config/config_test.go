@@ -0,0 +1,255 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfig writes a configuration file in a temporary directory
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), FileName)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// isolateSearchPaths points the lookup locations at empty temporary directories
+func isolateSearchPaths(t *testing.T) (string, string) {
+	t.Helper()
+
+	userDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", userDir)
+
+	originalSystemConfigDir := systemConfigDir
+	systemConfigDir = t.TempDir()
+	t.Cleanup(func() { systemConfigDir = originalSystemConfigDir })
+
+	return filepath.Join(userDir, "chuck"), systemConfigDir
+}
+
+func TestDefault(t *testing.T) {
+	config := Default()
+	assert.NoError(t, config.Validate())
+	assert.Equal(t, "warn", config.Log.Level)
+	assert.Equal(t, "text", config.Output.Format)
+	assert.Equal(t, 6*time.Hour, config.Cache.TTL)
+}
+
+func TestLoad_NoFile(t *testing.T) {
+	isolateSearchPaths(t)
+
+	config, path, err := Load("")
+	require.NoError(t, err)
+	assert.Empty(t, path)
+	assert.Equal(t, Default(), config)
+}
+
+func TestLoad_SearchPaths(t *testing.T) {
+	userDir, systemDir := isolateSearchPaths(t)
+
+	systemPath := filepath.Join(systemDir, FileName)
+	require.NoError(t, os.WriteFile(systemPath, []byte("log:\n  level: info\n"), 0o600))
+
+	config, path, err := Load("")
+	require.NoError(t, err)
+	assert.Equal(t, systemPath, path)
+	assert.Equal(t, "info", config.Log.Level)
+
+	// The user configuration takes precedence over the system one
+	require.NoError(t, os.MkdirAll(userDir, 0o755))
+	userPath := filepath.Join(userDir, FileName)
+	require.NoError(t, os.WriteFile(userPath, []byte("log:\n  level: debug\n"), 0o600))
+
+	config, path, err = Load("")
+	require.NoError(t, err)
+	assert.Equal(t, userPath, path)
+	assert.Equal(t, "debug", config.Log.Level)
+}
+
+func TestLoad_ExplicitPath(t *testing.T) {
+	t.Setenv("GHCR_TOKEN", "secret")
+	path := writeConfig(t, `
+log:
+  level: debug
+  format: json
+output:
+  format: json
+  file: /var/lib/chuck/report.json
+cache:
+  ttl: 30m
+parallelism: 8
+registries:
+  - host: ghcr.io
+    username: bot
+    password: ${GHCR_TOKEN}
+  - host: harbor.example.com
+    type: harbor
+    endpoint: https://harbor.example.com:8443
+    username: robot$$library+chuck
+    password: ${GHCR_TOKEN}
+filters:
+  exclude:
+    - name=sidecar-*
+notifications:
+  - type: telegram
+    token: "123:abc"
+    chatId: "42"
+`)
+
+	config, loadedPath, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, path, loadedPath)
+	assert.NoError(t, config.Validate())
+
+	assert.Equal(t, LogConfig{Level: "debug", Format: "json"}, config.Log)
+	assert.Equal(t, OutputConfig{Format: "json", File: "/var/lib/chuck/report.json"}, config.Output)
+	assert.Equal(t, 30*time.Minute, config.Cache.TTL)
+	assert.Equal(t, 8, config.Parallelism)
+	// Settings missing from the file keep their default
+	assert.Equal(t, 2, config.RegistryParallelism)
+	assert.Equal(t, []RegistryConfig{
+		{Host: "ghcr.io", Username: "bot", Password: "secret"},
+		{Host: "harbor.example.com", Type: "harbor", Endpoint: "https://harbor.example.com:8443", Username: "robot$library+chuck", Password: "secret"},
+	}, config.Registries)
+	assert.Equal(t, []string{"name=sidecar-*"}, config.Filters.Exclude)
+	assert.Equal(t, []NotificationConfig{{Type: "telegram", Token: "123:abc", ChatID: "42"}}, config.Notifications)
+}
+
+func TestLoad_MissingExplicitPath(t *testing.T) {
+	_, _, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestLoad_UnknownKey(t *testing.T) {
+	path := writeConfig(t, "log:\n  levle: debug\n")
+
+	_, _, err := Load(path)
+	assert.ErrorContains(t, err, "levle")
+}
+
+func TestLoad_EnvOverrides(t *testing.T) {
+	path := writeConfig(t, "log:\n  level: info\noutput:\n  format: tab\n")
+	t.Setenv("CHUCK_LOG_LEVEL", "error")
+	t.Setenv("CHUCK_OUTPUT_ALL", "true")
+	t.Setenv("CHUCK_CACHE_TTL", "1h")
+	t.Setenv("CHUCK_PARALLELISM", "16")
+	t.Setenv("CHUCK_VERSIONS_POLICY", "minor")
+	t.Setenv("CHUCK_OCI_FALLBACK", "false")
+
+	config, _, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "error", config.Log.Level)
+	assert.Equal(t, "tab", config.Output.Format)
+	assert.True(t, config.Output.All)
+	assert.Equal(t, time.Hour, config.Cache.TTL)
+	assert.Equal(t, 16, config.Parallelism)
+	assert.Equal(t, "minor", config.Versions.Policy)
+	assert.False(t, config.OCIFallback)
+}
+
+func TestLoad_InvalidEnv(t *testing.T) {
+	isolateSearchPaths(t)
+	t.Setenv("CHUCK_CACHE_TTL", "forever")
+
+	_, _, err := Load("")
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "CHUCK_CACHE_TTL", validationErr.Key)
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		modify func(c *Config)
+		keys   []string
+	}{
+		{
+			name:   "Invalid log level",
+			modify: func(c *Config) { c.Log.Level = "verbose" },
+			keys:   []string{"log.level"},
+		},
+		{
+			name:   "Invalid output format",
+			modify: func(c *Config) { c.Output.Format = "xml" },
+			keys:   []string{"output.format"},
+		},
+		{
+			name:   "Invalid parallelism",
+			modify: func(c *Config) { c.Parallelism = 0; c.RegistryParallelism = -1 },
+			keys:   []string{"parallelism", "registryParallelism"},
+		},
+		{
+			name: "Invalid registries",
+			modify: func(c *Config) {
+				c.Registries = []RegistryConfig{
+					{Host: "ghcr.io"},
+					{Host: "https://quay.io"},
+					{Host: "ghcr.io"},
+					{Host: "registry.example.com", Password: "secret"},
+				}
+			},
+			keys: []string{"registries[1].host", "registries[2].host", "registries[3].username"},
+		},
+		{
+			name: "Invalid registry types",
+			modify: func(c *Config) {
+				c.Registries = []RegistryConfig{
+					{Host: "harbor.example.com", Type: "harbour"},
+					{Host: "nexus.example.com", Type: "nexus", Endpoint: "nexus.example.com:8083", Repository: "docker-hosted"},
+					{Host: "art.example.com", Type: "artifactory", Repository: "docker-local", APIKey: "key", Token: "token"},
+					{Host: "registry.example.com", Repository: "docker", APIKey: "key"},
+					{Host: "registry.gitlab.example.com", Type: "gitlab", AuthEndpoint: "gitlab.example.com/jwt/auth"},
+					{Host: "registry.other.example.com", AuthEndpoint: "https://other.example.com/jwt/auth"},
+					{Host: "europe-west1-docker.pkg.dev", KeyFile: "/etc/chuck/gcp.json", Username: "_json_key"},
+				}
+			},
+			keys: []string{
+				"registries[0].type", "registries[1].endpoint", "registries[2].apiKey", "registries[3].repository",
+				"registries[3].apiKey", "registries[4].authEndpoint", "registries[5].authEndpoint", "registries[6].keyFile",
+			},
+		},
+		{
+			name: "Invalid filters",
+			modify: func(c *Config) {
+				c.Filters.Include = []string{"name=web-*", "owner=me"}
+				c.Filters.Exclude = []string{"tag=~^("}
+			},
+			keys: []string{"filters.include[1]", "filters.exclude[0]"},
+		},
+		{
+			name: "Invalid versions",
+			modify: func(c *Config) {
+				c.Versions.Policy = "latest"
+				c.Versions.Prerelease = "sometimes"
+				c.Versions.IgnoreMetadata = []string{"-r[0-9]+$", "-r[0-9"}
+			},
+			keys: []string{"versions.policy", "versions.prerelease", "versions.ignoreMetadata[1]"},
+		},
+		{
+			name: "Invalid notifications",
+			modify: func(c *Config) {
+				c.Notifications = []NotificationConfig{{Type: "telegram", Token: "abc"}, {Type: "pigeon"}}
+			},
+			keys: []string{"notifications[0].chatId", "notifications[1].type"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := Default()
+			tc.modify(config)
+
+			err := config.Validate()
+			require.Error(t, err)
+			for _, key := range tc.keys {
+				assert.Contains(t, err.Error(), key+":")
+			}
+		})
+	}
+}
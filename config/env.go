@@ -0,0 +1,78 @@
+package config
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// envPrefix is the prefix of the environment variables overriding the configuration
+const envPrefix = "CHUCK_"
+
+// envOverride binds an environment variable to a setting
+type envOverride struct {
+	name  string
+	apply func(c *Config, value string) error
+}
+
+// envOverrides lists the settings which can be overridden by environment variables
+var envOverrides = []envOverride{
+	{"LOG_LEVEL", func(c *Config, v string) error { c.Log.Level = v; return nil }},
+	{"LOG_FORMAT", func(c *Config, v string) error { c.Log.Format = v; return nil }},
+	{"OUTPUT_FORMAT", func(c *Config, v string) error { c.Output.Format = v; return nil }},
+	{"OUTPUT_FILE", func(c *Config, v string) error { c.Output.File = v; return nil }},
+	{"OUTPUT_ALL", func(c *Config, v string) error { return parseBool(v, &c.Output.All) }},
+	{"CACHE_DISABLED", func(c *Config, v string) error { return parseBool(v, &c.Cache.Disabled) }},
+	{"CACHE_TTL", func(c *Config, v string) error { return parseDuration(v, &c.Cache.TTL) }},
+	{"CACHE_DIR", func(c *Config, v string) error { c.Cache.Dir = v; return nil }},
+	{"PARALLELISM", func(c *Config, v string) error { return parseInt(v, &c.Parallelism) }},
+	{"REGISTRY_PARALLELISM", func(c *Config, v string) error { return parseInt(v, &c.RegistryParallelism) }},
+	{"DOCKER_CONFIG", func(c *Config, v string) error { c.DockerConfig = v; return nil }},
+	{"DOCKERHUB_MAX_PAGES", func(c *Config, v string) error { return parseInt(v, &c.DockerHubMaxPages) }},
+	{"OCI_FALLBACK", func(c *Config, v string) error { return parseBool(v, &c.OCIFallback) }},
+	{"DB_PATH", func(c *Config, v string) error { c.DBPath = v; return nil }},
+	{"VERSIONS_POLICY", func(c *Config, v string) error { c.Versions.Policy = v; return nil }},
+	{"VERSIONS_PRERELEASE", func(c *Config, v string) error { c.Versions.Prerelease = v; return nil }},
+}
+
+// applyEnv overrides the settings set in the environment (e.g. CHUCK_LOG_LEVEL=debug)
+func applyEnv(config *Config, lookupEnv func(string) (string, bool)) error {
+	var errs []error
+	for _, override := range envOverrides {
+		value, ok := lookupEnv(envPrefix + override.name)
+		if !ok {
+			continue
+		}
+		if err := override.apply(config, value); err != nil {
+			errs = append(errs, &ValidationError{Key: envPrefix + override.name, Message: err.Error()})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func parseBool(value string, target *bool) error {
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return errors.New("invalid boolean " + strconv.Quote(value))
+	}
+	*target = parsed
+	return nil
+}
+
+func parseInt(value string, target *int) error {
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return errors.New("invalid integer " + strconv.Quote(value))
+	}
+	*target = parsed
+	return nil
+}
+
+func parseDuration(value string, target *time.Duration) error {
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return errors.New("invalid duration " + strconv.Quote(value))
+	}
+	*target = parsed
+	return nil
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/FedericoAntoniazzi/chuck/core"
+)
+
+var (
+	// LogLevels lists the accepted values of log.level
+	LogLevels = []string{"debug", "info", "warn", "error"}
+	// LogFormats lists the accepted values of log.format
+	LogFormats = []string{"text", "json"}
+	// OutputFormats lists the accepted values of output.format
+	OutputFormats = []string{"text", "tab", "json", "yaml", "csv"}
+	// RegistryTypes lists the accepted values of registries[].type
+	RegistryTypes = []string{"oci", "harbor", "nexus", "artifactory", "gitlab"}
+	// NotificationTypes lists the accepted values of notifications[].type
+	NotificationTypes = []string{"telegram", "webhook"}
+)
+
+// ValidationError reports an invalid setting along with its key (e.g. registries[1].host)
+type ValidationError struct {
+	Key     string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Key, e.Message)
+}
+
+// Validate checks every setting and returns all the problems found
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(key, format string, args ...any) {
+		errs = append(errs, &ValidationError{Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+	oneOf := func(key, value string, allowed []string) {
+		if !slices.Contains(allowed, strings.ToLower(value)) {
+			invalid(key, "invalid value %q (expected one of: %s)", value, strings.Join(allowed, ", "))
+		}
+	}
+	httpURL := func(key, value string) {
+		if parsed, err := url.Parse(value); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			invalid(key, "must be an http or https URL, got %q", value)
+		}
+	}
+
+	oneOf("log.level", c.Log.Level, LogLevels)
+	oneOf("log.format", c.Log.Format, LogFormats)
+	oneOf("output.format", c.Output.Format, OutputFormats)
+
+	if c.Cache.TTL < 0 {
+		invalid("cache.ttl", "must not be negative")
+	}
+	if c.Parallelism < 1 {
+		invalid("parallelism", "must be at least 1")
+	}
+	if c.RegistryParallelism < 1 {
+		invalid("registryParallelism", "must be at least 1")
+	}
+	if c.DockerHubMaxPages < 1 {
+		invalid("dockerHubMaxPages", "must be at least 1")
+	}
+
+	seenHosts := make(map[string]bool)
+	for i, registry := range c.Registries {
+		key := fmt.Sprintf("registries[%d]", i)
+		switch {
+		case registry.Host == "":
+			invalid(key+".host", "is required")
+		case strings.Contains(registry.Host, "://") || strings.Contains(registry.Host, "/"):
+			invalid(key+".host", "must be a host name without scheme nor path, got %q", registry.Host)
+		case seenHosts[registry.Host]:
+			invalid(key+".host", "duplicate registry %q", registry.Host)
+		}
+		seenHosts[registry.Host] = true
+
+		if registry.Password != "" && registry.Username == "" {
+			invalid(key+".username", "is required when password is set")
+		}
+		if registry.Token != "" && registry.Password != "" {
+			invalid(key+".token", "cannot be combined with password")
+		}
+
+		registryType := strings.ToLower(registry.Type)
+		if registryType != "" {
+			oneOf(key+".type", registry.Type, RegistryTypes)
+		}
+		if registry.Endpoint != "" {
+			httpURL(key+".endpoint", registry.Endpoint)
+		}
+		if registry.AuthEndpoint != "" {
+			if registryType != "gitlab" {
+				invalid(key+".authEndpoint", "is only supported by gitlab registries")
+			}
+			httpURL(key+".authEndpoint", registry.AuthEndpoint)
+		}
+		if registry.Repository != "" && registryType != "nexus" && registryType != "artifactory" {
+			invalid(key+".repository", "is only supported by nexus and artifactory registries")
+		}
+		if registry.APIKey != "" {
+			if registryType != "artifactory" {
+				invalid(key+".apiKey", "is only supported by artifactory registries")
+			}
+			if registry.Password != "" || registry.Token != "" {
+				invalid(key+".apiKey", "cannot be combined with password nor token")
+			}
+		}
+		if registry.KeyFile != "" && (registry.Username != "" || registry.Password != "" || registry.Token != "") {
+			invalid(key+".keyFile", "cannot be combined with username, password nor token")
+		}
+	}
+
+	for i, rule := range c.Filters.Include {
+		if _, err := core.ParseFilterRule(rule); err != nil {
+			invalid(fmt.Sprintf("filters.include[%d]", i), "%v", err)
+		}
+	}
+	for i, rule := range c.Filters.Exclude {
+		if _, err := core.ParseFilterRule(rule); err != nil {
+			invalid(fmt.Sprintf("filters.exclude[%d]", i), "%v", err)
+		}
+	}
+
+	if _, err := core.ParsePolicyLevel(c.Versions.Policy); err != nil {
+		invalid("versions.policy", "%v", err)
+	}
+	if _, err := core.ParsePrereleasePolicy(c.Versions.Prerelease); err != nil {
+		invalid("versions.prerelease", "%v", err)
+	}
+	for i, pattern := range c.Versions.IgnoreMetadata {
+		if _, err := core.ParseMetadataPatterns([]string{pattern}); err != nil {
+			invalid(fmt.Sprintf("versions.ignoreMetadata[%d]", i), "%v", err)
+		}
+	}
+
+	for i, notification := range c.Notifications {
+		key := fmt.Sprintf("notifications[%d]", i)
+		oneOf(key+".type", notification.Type, NotificationTypes)
+
+		switch strings.ToLower(notification.Type) {
+		case "telegram":
+			if notification.Token == "" {
+				invalid(key+".token", "is required for telegram notifications")
+			}
+			if notification.ChatID == "" {
+				invalid(key+".chatId", "is required for telegram notifications")
+			}
+		case "webhook":
+			if notification.URL == "" {
+				invalid(key+".url", "is required for webhook notifications")
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
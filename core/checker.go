@@ -0,0 +1,443 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/registry"
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"github.com/docker/docker/api/types/container"
+	"go.uber.org/zap"
+)
+
+// ContainerSource lists the containers whose images are checked for updates
+type ContainerSource interface {
+	ListContainers(ctx context.Context) ([]container.Summary, error)
+}
+
+// ContainerSourceFunc adapts a function to the ContainerSource interface
+type ContainerSourceFunc func(ctx context.Context) ([]container.Summary, error)
+
+// ListContainers calls f(ctx)
+func (f ContainerSourceFunc) ListContainers(ctx context.Context) ([]container.Summary, error) {
+	return f(ctx)
+}
+
+// Checker scans containers and looks for newer versions of their images in the registries
+type Checker struct {
+	source                ContainerSource
+	registryClients       map[string]RegistryClient
+	defaultRegistryClient RegistryClient
+	parallelism           int
+	registryParallelism   int
+	filter                *Filter
+	showSkipped           bool
+	policy                UpdatePolicy
+	digestClient          DigestClient
+	digestClients         map[string]DigestClient
+	logger                *zap.SugaredLogger
+}
+
+// CheckerOption configures a Checker
+type CheckerOption func(*Checker)
+
+// WithRegistryClient sets the client used for images hosted on registry (e.g. docker.io).
+// registry may also be a glob pattern (e.g. *.azurecr.io), used when no exact name matches.
+func WithRegistryClient(registry string, client RegistryClient) CheckerOption {
+	return func(c *Checker) {
+		c.registryClients[registry] = client
+	}
+}
+
+// WithDefaultRegistryClient sets the client used for registries without a dedicated client.
+// Without it, images of those registries are reported as unsupported.
+func WithDefaultRegistryClient(client RegistryClient) CheckerOption {
+	return func(c *Checker) {
+		c.defaultRegistryClient = client
+	}
+}
+
+// WithParallelism limits the number of concurrent tag listings, overall and per registry
+func WithParallelism(parallelism, registryParallelism int) CheckerOption {
+	return func(c *Checker) {
+		c.parallelism = parallelism
+		c.registryParallelism = registryParallelism
+	}
+}
+
+// WithFilter selects the containers to check before any registry call.
+// When showSkipped is set, filtered out containers are reported as skipped instead of omitted.
+func WithFilter(filter *Filter, showSkipped bool) CheckerOption {
+	return func(c *Checker) {
+		c.filter = filter
+		c.showSkipped = showSkipped
+	}
+}
+
+// WithUpdatePolicy sets the update policy of the containers, which their labels can override
+func WithUpdatePolicy(policy UpdatePolicy) CheckerOption {
+	return func(c *Checker) {
+		c.policy = policy
+	}
+}
+
+// WithDigestClient enables the digest comparison of containers running a non-semver tag (e.g. latest):
+// the digest of the running image is compared with the one currently published under the same tag.
+// It requires a ContainerSource implementing RepoDigestSource.
+func WithDigestClient(client DigestClient) CheckerOption {
+	return func(c *Checker) {
+		c.digestClient = client
+	}
+}
+
+// WithRegistryDigestClient sets the digest client used for images hosted on registry,
+// in place of the one set with WithDigestClient. registry may be a glob pattern, as in WithRegistryClient.
+func WithRegistryDigestClient(registry string, client DigestClient) CheckerOption {
+	return func(c *Checker) {
+		c.digestClients[registry] = client
+	}
+}
+
+// WithLogger sets the logger of the Checker
+func WithLogger(logger *zap.SugaredLogger) CheckerOption {
+	return func(c *Checker) {
+		c.logger = logger
+	}
+}
+
+// NewChecker creates a Checker inspecting the containers listed by source
+func NewChecker(source ContainerSource, opts ...CheckerOption) *Checker {
+	checker := &Checker{
+		source:              source,
+		registryClients:     make(map[string]RegistryClient),
+		digestClients:       make(map[string]DigestClient),
+		parallelism:         DefaultParallelism,
+		registryParallelism: DefaultRegistryParallelism,
+		policy:              DefaultUpdatePolicy(),
+		logger:              zap.NewNop().Sugar(),
+	}
+	for _, opt := range opts {
+		opt(checker)
+	}
+	return checker
+}
+
+// Check lists the containers and returns the update status of each of them, in listing order.
+// Failures concerning a single container are reported in its status rather than as an error.
+func (c *Checker) Check(ctx context.Context) ([]types.ImageUpdateStatus, error) {
+	containers, err := c.source.ListContainers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	c.logger.Infof("Found %d running containers", len(containers))
+
+	var allUpdateStatuses []types.ImageUpdateStatus
+	// Containers waiting for the tags of their image, by position in allUpdateStatuses
+	pendingChecks := make(map[int]*pendingCheck)
+	var tagJobs []TagJob
+
+	skipped := 0
+	for _, cnt := range containers {
+		status, pending := c.inspect(ctx, cnt)
+		if status.Status.Skipped() {
+			skipped++
+			if !c.showSkipped {
+				continue
+			}
+		}
+		if pending != nil {
+			tagJobs = append(tagJobs, pending.job)
+			if pending.digest != nil {
+				tagJobs = append(tagJobs, pending.digest.job)
+			}
+			pendingChecks[len(allUpdateStatuses)] = pending
+		}
+		allUpdateStatuses = append(allUpdateStatuses, status)
+	}
+
+	if skipped > 0 {
+		c.logger.Infof("Skipped %d containers by filter or label", skipped)
+	}
+
+	// Fetch image tags and digests from registries concurrently
+	tagFetcher := NewTagFetcher(c.parallelism, c.registryParallelism, c.logger)
+	tagResults := tagFetcher.FetchAll(ctx, tagJobs)
+
+	// Compare versions in container order to keep the results deterministic
+	for pos, pending := range pendingChecks {
+		status := c.compare(allUpdateStatuses[pos], pending, tagResults[pending.job.Key])
+		if pending.digest != nil && status.Status == types.StatusUpToDate {
+			status = c.compareFloating(status, pending.digest, tagResults[pending.digest.job.Key])
+		}
+		allUpdateStatuses[pos] = status
+	}
+
+	return allUpdateStatuses, nil
+}
+
+// pendingCheck is a container waiting for the tags of its image,
+// or for the digest of its tag when runningDigest is set
+type pendingCheck struct {
+	job           TagJob
+	policy        UpdatePolicy
+	runningDigest string
+	// digest is the digest check of floating tags (e.g. 7 or 1.25), which are
+	// re-pushed with each release of the versions they cover
+	digest *pendingCheck
+}
+
+// inspect builds the initial status of a container and, when its image can be checked,
+// the pending check listing the tags of its repository
+func (c *Checker) inspect(ctx context.Context, cnt container.Summary) (types.ImageUpdateStatus, *pendingCheck) {
+	containerName := ""
+
+	if len(cnt.Names) > 0 && len(cnt.Names[0]) > 0 {
+		containerName = strings.TrimPrefix(cnt.Names[0], "/")
+	}
+
+	c.logger.Debug("processing container ", containerName)
+
+	status := types.ImageUpdateStatus{
+		ContainerID:   cnt.ID,
+		ContainerName: containerName,
+		OriginalTag:   "latest",
+		StatusMessage: "Processing",
+	}
+
+	image, err := ParseImageName(cnt.Image)
+
+	// Filter rules on the container name and labels apply even to unparsable images
+	if skip, reason := c.filter.Skip(cnt, containerName, image); skip {
+		status.Image = image
+		status.OriginalTag = image.Tag
+		status.Status = types.StatusSkipped
+		status.StatusMessage = "Skipped by filter: " + reason
+		c.logger.Debugw("skipping container by filter", "container", containerName, "image", cnt.Image, "reason", reason)
+		return status, nil
+	}
+
+	policy, enabled, policyErr := c.policy.WithLabels(cnt.Labels)
+	if !enabled {
+		status.Image = image
+		status.OriginalTag = image.Tag
+		status.Status = types.StatusDisabled
+		status.StatusMessage = "Disabled by label: " + LabelEnable + "=false"
+		c.logger.Debugw("skipping disabled container", "container", containerName, "image", cnt.Image)
+		return status, nil
+	}
+
+	if err != nil {
+		status.Status = types.StatusInvalidImage
+		status.StatusMessage = "Error parsing image name"
+		status.Error = err.Error()
+		c.logger.Warnw("skipping invalid image name", "image", cnt.Image, "error", err)
+		return status, nil
+	}
+
+	status.Image = image
+	status.OriginalTag = image.Tag
+
+	if policyErr != nil {
+		status.Status = types.StatusInvalidLabel
+		status.StatusMessage = "Error parsing update policy labels"
+		status.Error = policyErr.Error()
+		c.logger.Warnw("skipping container with invalid update policy", "container", containerName, "error", policyErr)
+		return status, nil
+	}
+
+	// Check if the registry is supported
+	regClient, ok := lookupRegistry(c.registryClients, image.Registry)
+	if !ok {
+		regClient = c.defaultRegistryClient
+	}
+	if regClient == nil {
+		status.Status = types.StatusUnsupportedRegistry
+		status.StatusMessage = "Unsupported registry"
+		status.Error = "Unsupported registry"
+		c.logger.Warn("skipping unsupported registry (", image.Registry, ") for image ", image.Raw)
+		return status, nil
+	}
+
+	// Check if the tag starts with a valid SemVer, optionally followed by a variant suffix
+	tag, err := ParseTag(image.Tag)
+	if err != nil {
+		// Mutable tags (e.g. latest) are checked by digest instead
+		if pending := c.inspectDigest(ctx, cnt, image); pending != nil {
+			status.CurrentDigest = pending.runningDigest
+			return status, pending
+		}
+
+		status.Status = types.StatusNonSemverTag
+		status.StatusMessage = "Error parsing image tag"
+		status.Error = err.Error()
+		c.logger.Warnw("skipping invalid semver tag", "image", image.Raw, "tag", image.Tag)
+		return status, nil
+	}
+
+	imageKey := fmt.Sprintf("%s/%s/%s", image.Registry, image.Namespace, image.Name)
+	pending := &pendingCheck{
+		job:    TagJob{Key: imageKey, Image: image, Client: regClient},
+		policy: policy,
+	}
+
+	// Floating tags are also checked by digest, as they are moved to each new patch
+	if tag.Precision < 3 {
+		pending.digest = c.inspectDigest(ctx, cnt, image)
+		if pending.digest != nil {
+			status.CurrentDigest = pending.digest.runningDigest
+		}
+	}
+
+	return status, pending
+}
+
+// inspectDigest builds the pending digest check of a container, or returns nil
+// when digests cannot be compared
+func (c *Checker) inspectDigest(ctx context.Context, cnt container.Summary, image types.Image) *pendingCheck {
+	digestClient, ok := lookupRegistry(c.digestClients, image.Registry)
+	if !ok {
+		digestClient = c.digestClient
+	}
+	digestSource, ok := c.source.(RepoDigestSource)
+	if digestClient == nil || !ok {
+		return nil
+	}
+
+	repoDigests, err := digestSource.RepoDigests(ctx, cnt.ImageID)
+	if err != nil {
+		c.logger.Warnw("could not inspect the running image", "image", image.Raw, "error", err)
+		return nil
+	}
+	runningDigest, err := repoDigest(repoDigests, image)
+	if err != nil {
+		c.logger.Debugw("skipping digest check", "image", image.Raw, "error", err)
+		return nil
+	}
+
+	imageKey := fmt.Sprintf("%s/%s/%s:%s@digest", image.Registry, image.Namespace, image.Name, image.Tag)
+	return &pendingCheck{
+		job:           TagJob{Key: imageKey, Image: image, Client: digestLister{client: digestClient}},
+		runningDigest: runningDigest,
+	}
+}
+
+// compare completes the status of a container with the tags available for its image
+func (c *Checker) compare(status types.ImageUpdateStatus, pending *pendingCheck, result TagResult) types.ImageUpdateStatus {
+	imageKey := pending.job.Key
+
+	if result.Err != nil {
+		status.Status = types.StatusFetchError
+		status.StatusMessage = "Error fetching images"
+		if errors.Is(result.Err, registry.ErrRateLimited) {
+			status.StatusMessage = "Registry rate limit exceeded"
+		}
+		status.Error = result.Err.Error()
+		c.logger.Errorw("error retrieving tags from registry", "image", imageKey, "registry", status.Image.Registry, "error", result.Err)
+		return status
+	}
+
+	if pending.runningDigest != "" {
+		return c.compareDigest(status, pending, result.Tags[0])
+	}
+
+	updates, err := FindUpdates(status.Image.Tag, result.Tags, pending.policy)
+	if err != nil {
+		status.Status = types.StatusCompareError
+		status.StatusMessage = "Error comparing tags"
+		status.Error = err.Error()
+		c.logger.Errorw("unexpected error during semver checks", "error", err)
+		return status
+	}
+
+	status.UpdateAvailable = updates.Latest != ""
+	status.LatestAvailableTag = updates.Latest
+	status.LatestPatchTag = updates.Patch
+	status.LatestMinorTag = updates.Minor
+	status.LatestMajorTag = updates.Major
+	status.CurrentTagModified = tagModified(result.Infos, status.Image.Tag)
+	status.LatestTagModified = tagModified(result.Infos, updates.Latest)
+
+	if status.UpdateAvailable {
+		status.Status = types.StatusUpdateAvailable
+		status.StatusMessage = "Update available"
+		c.logger.Debugf("Container %s (%s) can be upgraded to %s", status.ContainerName, imageKey, updates.Latest)
+	} else {
+		status.Status = types.StatusUpToDate
+		status.StatusMessage = "No update available"
+		c.logger.Debugf("checked updates for %s (%s). No updates available", status.ContainerName, imageKey)
+	}
+
+	return status
+}
+
+// compareDigest completes the status of a container running a mutable tag
+// with the digest currently published under that tag
+func (c *Checker) compareDigest(status types.ImageUpdateStatus, pending *pendingCheck, latestDigest string) types.ImageUpdateStatus {
+	status.LatestDigest = latestDigest
+
+	if latestDigest != pending.runningDigest {
+		status.UpdateAvailable = true
+		status.LatestAvailableTag = status.Image.Tag
+		status.Status = types.StatusNewDigest
+		status.StatusMessage = "Newer image published under same tag"
+		c.logger.Debugf("Container %s (%s) runs %s, while %s is published", status.ContainerName, pending.job.Key, pending.runningDigest, latestDigest)
+	} else {
+		status.Status = types.StatusUpToDate
+		status.StatusMessage = "No update available"
+		c.logger.Debugf("checked digest for %s (%s). No updates available", status.ContainerName, pending.job.Key)
+	}
+
+	return status
+}
+
+// compareFloating completes the status of an up to date container running a floating tag
+// with the digest currently published under that tag. Digest lookup failures are only logged,
+// as the version check already succeeded.
+func (c *Checker) compareFloating(status types.ImageUpdateStatus, pending *pendingCheck, result TagResult) types.ImageUpdateStatus {
+	if result.Err != nil {
+		c.logger.Warnw("could not check the digest of floating tag", "image", status.Image.Raw, "error", result.Err)
+		return status
+	}
+	return c.compareDigest(status, pending, result.Tags[0])
+}
+
+// tagModified returns the last modification of tag among infos (RFC 3339), empty when unknown
+func tagModified(infos []types.TagInfo, tag string) string {
+	for _, info := range infos {
+		if info.Name == tag && !info.LastModified.IsZero() {
+			return info.LastModified.UTC().Format(time.RFC3339)
+		}
+	}
+	return ""
+}
+
+// lookupRegistry returns the value set for registry by exact name or, failing that,
+// by the first glob pattern matching it in lexical order
+func lookupRegistry[T any](values map[string]T, registry string) (T, bool) {
+	if value, ok := values[registry]; ok {
+		return value, true
+	}
+
+	patterns := make([]string, 0, len(values))
+	for pattern := range values {
+		if strings.ContainsAny(pattern, "*?[") {
+			patterns = append(patterns, pattern)
+		}
+	}
+	slices.Sort(patterns)
+
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, registry); matched {
+			return values[pattern], true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
@@ -0,0 +1,271 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/registry"
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+// staticRegistry is a RegistryClient returning fixed tags per repository
+type staticRegistry struct {
+	tags  map[string][]string
+	calls int
+}
+
+func (r *staticRegistry) GetTags(_ context.Context, image types.Image) ([]string, error) {
+	r.calls++
+	tags, ok := r.tags[image.Namespace+"/"+image.Name]
+	if !ok {
+		return nil, fmt.Errorf("repository %s/%s not found", image.Namespace, image.Name)
+	}
+	return tags, nil
+}
+
+// rateLimitedRegistry refuses every request
+type rateLimitedRegistry struct{}
+
+func (rateLimitedRegistry) GetTags(context.Context, types.Image) ([]string, error) {
+	return nil, fmt.Errorf("%w, retry later", registry.ErrRateLimited)
+}
+
+// infoRegistry is a TagInfoLister returning fixed tags with their metadata
+type infoRegistry struct {
+	infos []types.TagInfo
+}
+
+func (r infoRegistry) GetTags(context.Context, types.Image) ([]string, error) {
+	return nil, fmt.Errorf("tags should be listed with their metadata")
+}
+
+func (r infoRegistry) GetTagInfos(context.Context, types.Image) ([]types.TagInfo, error) {
+	return r.infos, nil
+}
+
+func staticSource(containers ...container.Summary) ContainerSource {
+	return ContainerSourceFunc(func(context.Context) ([]container.Summary, error) {
+		return containers, nil
+	})
+}
+
+func TestChecker_Check(t *testing.T) {
+	source := staticSource(
+		container.Summary{ID: "1", Names: []string{"/web"}, Image: "nginx:1.25"},
+		container.Summary{ID: "2", Names: []string{"/cache"}, Image: "redis:7.2.0"},
+		container.Summary{ID: "3", Names: []string{"/web-2"}, Image: "nginx:1.25"},
+		container.Summary{ID: "4", Names: []string{"/floating"}, Image: "nginx:latest"},
+		container.Summary{ID: "5", Names: []string{"/private"}, Image: "registry.example.com/team/app:1.0.0"},
+		container.Summary{ID: "6", Names: []string{"/missing"}, Image: "library/missing:1.0.0"},
+		container.Summary{ID: "7", Names: []string{"/invalid"}, Image: "INVALID::image"},
+	)
+	dockerHub := &staticRegistry{tags: map[string][]string{
+		"library/nginx": {"1.25", "1.27", "latest"},
+		"library/redis": {"7.0.0", "7.2.0"},
+	}}
+
+	checker := NewChecker(source, WithRegistryClient("docker.io", dockerHub))
+	statuses, err := checker.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() unexpected error = %v", err)
+	}
+
+	expected := []struct {
+		name            string
+		updateAvailable bool
+		latest          string
+		status          types.CheckStatus
+		message         string
+		hasError        bool
+	}{
+		{name: "web", updateAvailable: true, latest: "1.27", status: types.StatusUpdateAvailable, message: "Update available"},
+		{name: "cache", status: types.StatusUpToDate, message: "No update available"},
+		{name: "web-2", updateAvailable: true, latest: "1.27", status: types.StatusUpdateAvailable, message: "Update available"},
+		{name: "floating", status: types.StatusNonSemverTag, message: "Error parsing image tag", hasError: true},
+		{name: "private", status: types.StatusUnsupportedRegistry, message: "Unsupported registry", hasError: true},
+		{name: "missing", status: types.StatusFetchError, message: "Error fetching images", hasError: true},
+		{name: "invalid", status: types.StatusInvalidImage, message: "Error parsing image name", hasError: true},
+	}
+
+	if len(statuses) != len(expected) {
+		t.Fatalf("Expected %d statuses, got %d", len(expected), len(statuses))
+	}
+	for i, exp := range expected {
+		got := statuses[i]
+		if got.ContainerName != exp.name || got.UpdateAvailable != exp.updateAvailable ||
+			got.LatestAvailableTag != exp.latest || got.Status != exp.status || got.StatusMessage != exp.message || (got.Error != "") != exp.hasError {
+			t.Errorf("Unexpected status #%d: %+v", i, got)
+		}
+	}
+
+	// nginx is listed once for both containers
+	if dockerHub.calls != 3 {
+		t.Errorf("Expected 3 tag listings, got %d", dockerHub.calls)
+	}
+}
+
+func TestChecker_DefaultRegistryClient(t *testing.T) {
+	source := staticSource(container.Summary{ID: "1", Names: []string{"/app"}, Image: "registry.example.com/team/app:1.0.0"})
+	generic := &staticRegistry{tags: map[string][]string{"team/app": {"1.0.0", "1.1.0"}}}
+
+	statuses, err := NewChecker(source, WithDefaultRegistryClient(generic)).Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() unexpected error = %v", err)
+	}
+	if len(statuses) != 1 || !statuses[0].UpdateAvailable || statuses[0].LatestAvailableTag != "1.1.0" {
+		t.Errorf("Unexpected statuses: %+v", statuses)
+	}
+}
+
+func TestChecker_RateLimited(t *testing.T) {
+	source := staticSource(container.Summary{ID: "1", Names: []string{"/web"}, Image: "nginx:1.25"})
+
+	statuses, err := NewChecker(source, WithRegistryClient("docker.io", rateLimitedRegistry{})).Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() unexpected error = %v", err)
+	}
+	if statuses[0].StatusMessage != "Registry rate limit exceeded" {
+		t.Errorf("Unexpected status message %q", statuses[0].StatusMessage)
+	}
+}
+
+func TestChecker_SourceError(t *testing.T) {
+	source := ContainerSourceFunc(func(context.Context) ([]container.Summary, error) {
+		return nil, errors.New("daemon unreachable")
+	})
+
+	_, err := NewChecker(source).Check(context.Background())
+	if err == nil {
+		t.Error("Expected an error when containers cannot be listed")
+	}
+}
+
+func TestChecker_Filter(t *testing.T) {
+	source := staticSource(
+		container.Summary{ID: "1", Names: []string{"/web"}, Image: "nginx:1.25"},
+		container.Summary{ID: "2", Names: []string{"/web-sidecar"}, Image: "envoyproxy/envoy:1.30.0"},
+	)
+	dockerHub := &staticRegistry{tags: map[string][]string{"library/nginx": {"1.25", "1.27"}}}
+	filter, err := NewFilter(nil, []string{"name=*-sidecar"})
+	if err != nil {
+		t.Fatalf("NewFilter() unexpected error = %v", err)
+	}
+
+	statuses, err := NewChecker(source, WithRegistryClient("docker.io", dockerHub), WithFilter(filter, false)).Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() unexpected error = %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].ContainerName != "web" {
+		t.Errorf("Expected only the web container, got %+v", statuses)
+	}
+	if dockerHub.calls != 1 {
+		t.Errorf("Expected 1 registry call, got %d", dockerHub.calls)
+	}
+
+	statuses, err = NewChecker(source, WithRegistryClient("docker.io", dockerHub), WithFilter(filter, true)).Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() unexpected error = %v", err)
+	}
+	if len(statuses) != 2 || statuses[1].Status != types.StatusSkipped {
+		t.Errorf("Expected the sidecar to be reported as skipped, got %+v", statuses)
+	}
+}
+
+func TestChecker_PolicyLabels(t *testing.T) {
+	source := staticSource(
+		container.Summary{ID: "1", Names: []string{"/db"}, Image: "postgres:13.2.0", Labels: map[string]string{LabelPolicy: "minor"}},
+		container.Summary{ID: "2", Names: []string{"/legacy"}, Image: "postgres:12.0.0", Labels: map[string]string{LabelEnable: "false"}},
+		container.Summary{ID: "3", Names: []string{"/broken"}, Image: "postgres:13.2.0", Labels: map[string]string{LabelPolicy: "sometimes"}},
+	)
+	dockerHub := &staticRegistry{tags: map[string][]string{"library/postgres": {"12.0.0", "13.2.0", "13.9.0", "17.0.0"}}}
+
+	statuses, err := NewChecker(source, WithRegistryClient("docker.io", dockerHub), WithFilter(nil, true)).Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() unexpected error = %v", err)
+	}
+	if len(statuses) != 3 {
+		t.Fatalf("Expected 3 statuses, got %d", len(statuses))
+	}
+	if statuses[0].LatestAvailableTag != "13.9.0" {
+		t.Errorf("Expected the minor policy to suggest 13.9.0, got %q", statuses[0].LatestAvailableTag)
+	}
+	if statuses[1].Status != types.StatusDisabled {
+		t.Errorf("Expected the disabled container to be reported as disabled, got %q", statuses[1].Status)
+	}
+	if statuses[2].Status != types.StatusInvalidLabel || statuses[2].Error == "" {
+		t.Errorf("Expected an invalid label status, got %+v", statuses[2])
+	}
+}
+
+func TestChecker_DefaultPolicy(t *testing.T) {
+	source := staticSource(
+		container.Summary{ID: "1", Names: []string{"/db"}, Image: "postgres:13.2.0"},
+		container.Summary{ID: "2", Names: []string{"/edge"}, Image: "postgres:13.2.0", Labels: map[string]string{LabelPolicy: "major"}},
+	)
+	dockerHub := &staticRegistry{tags: map[string][]string{"library/postgres": {"13.2.0", "13.9.0", "17.0.0"}}}
+
+	policy := DefaultUpdatePolicy()
+	policy.Level = PolicyMinor
+	statuses, err := NewChecker(source, WithRegistryClient("docker.io", dockerHub), WithUpdatePolicy(policy)).Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() unexpected error = %v", err)
+	}
+	if statuses[0].LatestAvailableTag != "13.9.0" {
+		t.Errorf("Expected the default minor policy to suggest 13.9.0, got %q", statuses[0].LatestAvailableTag)
+	}
+	if statuses[1].LatestAvailableTag != "17.0.0" {
+		t.Errorf("Expected the major policy label to override the default, got %q", statuses[1].LatestAvailableTag)
+	}
+}
+
+func TestChecker_TagModified(t *testing.T) {
+	source := staticSource(container.Summary{ID: "1", Names: []string{"/web"}, Image: "quay.io/nginx/nginx-unprivileged:1.25"})
+	quay := infoRegistry{infos: []types.TagInfo{
+		{Name: "1.25", LastModified: time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)},
+		{Name: "1.27"},
+	}}
+
+	statuses, err := NewChecker(source, WithRegistryClient("quay.io", quay)).Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() unexpected error = %v", err)
+	}
+
+	if statuses[0].LatestAvailableTag != "1.27" {
+		t.Errorf("Expected an update to 1.27, got %+v", statuses[0])
+	}
+	if statuses[0].CurrentTagModified != "2025-03-04T10:00:00Z" {
+		t.Errorf("CurrentTagModified = %q, want %q", statuses[0].CurrentTagModified, "2025-03-04T10:00:00Z")
+	}
+	if statuses[0].LatestTagModified != "" {
+		t.Errorf("LatestTagModified = %q, want empty for a tag without metadata", statuses[0].LatestTagModified)
+	}
+}
+
+func TestLookupRegistry(t *testing.T) {
+	clients := map[string]string{
+		"docker.io":                 "hub",
+		"*.azurecr.io":              "acr",
+		"*.dkr.ecr.*.amazonaws.com": "ecr",
+		"*-docker.pkg.dev":          "gar",
+		"special.azurecr.io":        "special",
+	}
+
+	testCases := map[string]string{
+		"docker.io":             "hub",
+		"myregistry.azurecr.io": "acr",
+		"special.azurecr.io":    "special",
+		"123456789012.dkr.ecr.eu-west-1.amazonaws.com": "ecr",
+		"europe-west1-docker.pkg.dev":                  "gar",
+		"quay.io":                                      "",
+	}
+	for registry, want := range testCases {
+		got, ok := lookupRegistry(clients, registry)
+		if got != want || ok != (want != "") {
+			t.Errorf("lookupRegistry(%q) = %q, %v, want %q", registry, got, ok, want)
+		}
+	}
+}
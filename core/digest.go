@@ -0,0 +1,67 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"github.com/distribution/reference"
+)
+
+// DigestClient resolves the manifest digest currently published under a tag
+type DigestClient interface {
+	// GetDigest returns the digest of the manifest of image in its registry (e.g. sha256:...)
+	GetDigest(ctx context.Context, image types.Image) (string, error)
+}
+
+// RepoDigestSource resolves the repository digests of local images, as reported by
+// the RepoDigests of docker image inspect (e.g. nginx@sha256:...)
+type RepoDigestSource interface {
+	RepoDigests(ctx context.Context, imageID string) ([]string, error)
+}
+
+// RepoDigests returns the repository digests of the image identified by imageID
+func (s *DockerSource) RepoDigests(ctx context.Context, imageID string) ([]string, error) {
+	inspect, err := s.cli.ImageInspect(ctx, imageID)
+	if err != nil {
+		return nil, fmt.Errorf("error inspecting image %s: %w", imageID, err)
+	}
+	return inspect.RepoDigests, nil
+}
+
+// digestLister adapts a DigestClient to the RegistryClient interface, so that digest lookups
+// run through the TagFetcher with the same parallelism limits as tag listings
+type digestLister struct {
+	client DigestClient
+}
+
+// GetTags returns the digest of the tag of image as the only element
+func (l digestLister) GetTags(ctx context.Context, image types.Image) ([]string, error) {
+	digest, err := l.client.GetDigest(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+	return []string{digest}, nil
+}
+
+// repoDigest returns the digest among repoDigests referring to the repository of image
+func repoDigest(repoDigests []string, image types.Image) (string, error) {
+	imageRef, err := reference.ParseNormalizedNamed(image.Raw)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse image reference: %w", err)
+	}
+
+	for _, repoDigest := range repoDigests {
+		ref, err := reference.ParseNormalizedNamed(repoDigest)
+		if err != nil {
+			continue
+		}
+		canonical, ok := ref.(reference.Canonical)
+		if !ok || ref.Name() != imageRef.Name() {
+			continue
+		}
+		return canonical.Digest().String(), nil
+	}
+
+	return "", fmt.Errorf("no repository digest of %s found for the running image", imageRef.Name())
+}
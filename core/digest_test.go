@@ -0,0 +1,168 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"go.uber.org/zap"
+)
+
+// staticDigests is a DigestClient returning fixed digests per image reference
+type staticDigests map[string]string
+
+func (d staticDigests) GetDigest(_ context.Context, image types.Image) (string, error) {
+	digest, ok := d[image.Raw]
+	if !ok {
+		return "", fmt.Errorf("manifest %s not found", image.Raw)
+	}
+	return digest, nil
+}
+
+func TestRepoDigest(t *testing.T) {
+	image, err := ParseImageName("nginx:latest")
+	if err != nil {
+		t.Fatalf("ParseImageName() unexpected error = %v", err)
+	}
+
+	repoDigests := []string{
+		"registry.example.com/mirror/nginx@sha256:1111111111111111111111111111111111111111111111111111111111111111",
+		"nginx@sha256:2222222222222222222222222222222222222222222222222222222222222222",
+	}
+	digest, err := repoDigest(repoDigests, image)
+	if err != nil {
+		t.Fatalf("repoDigest() unexpected error = %v", err)
+	}
+	if digest != "sha256:2222222222222222222222222222222222222222222222222222222222222222" {
+		t.Errorf("repoDigest() = %s, expected the digest of docker.io/library/nginx", digest)
+	}
+
+	if _, err := repoDigest(repoDigests[:1], image); err == nil {
+		t.Error("Expected an error when no digest refers to the repository")
+	}
+}
+
+func TestChecker_Digest(t *testing.T) {
+	const (
+		oldDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		newDigest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+
+	log := zap.NewNop().Sugar()
+	cli := &fakeDockerClient{
+		containers: []container.Summary{
+			{ID: "1", Names: []string{"/web"}, Image: "nginx:latest", ImageID: "sha256:aaa", State: container.StateRunning},
+			{ID: "2", Names: []string{"/cache"}, Image: "redis:alpine", ImageID: "sha256:bbb", State: container.StateRunning},
+			{ID: "3", Names: []string{"/local"}, Image: "app:dev", ImageID: "sha256:ccc", State: container.StateRunning},
+		},
+		images: map[string]image.InspectResponse{
+			"sha256:aaa": {RepoDigests: []string{"nginx@" + oldDigest}},
+			"sha256:bbb": {RepoDigests: []string{"redis@" + newDigest}},
+			"sha256:ccc": {},
+		},
+	}
+	digests := staticDigests{"nginx:latest": newDigest, "redis:alpine": newDigest}
+	dockerHub := &staticRegistry{tags: map[string][]string{}}
+
+	checker := NewChecker(NewDockerSource(cli, log),
+		WithRegistryClient("docker.io", dockerHub),
+		WithDigestClient(digests),
+		WithLogger(log),
+	)
+	statuses, err := checker.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() unexpected error = %v", err)
+	}
+
+	if statuses[0].Status != types.StatusNewDigest || !statuses[0].UpdateAvailable || statuses[0].LatestDigest != newDigest {
+		t.Errorf("Expected a newer image under the latest tag, got %+v", statuses[0])
+	}
+	if statuses[1].Status != types.StatusUpToDate || statuses[1].CurrentDigest != newDigest {
+		t.Errorf("Expected redis:alpine to be up to date, got %+v", statuses[1])
+	}
+	if statuses[2].Status != types.StatusNonSemverTag {
+		t.Errorf("Expected images without repository digest to keep the non-semver status, got %+v", statuses[2])
+	}
+	if dockerHub.calls != 0 {
+		t.Errorf("Expected no tag listing, got %d", dockerHub.calls)
+	}
+}
+
+func TestChecker_RegistryDigestClient(t *testing.T) {
+	const digest = "sha256:3333333333333333333333333333333333333333333333333333333333333333"
+
+	log := zap.NewNop().Sugar()
+	cli := &fakeDockerClient{
+		containers: []container.Summary{
+			{ID: "1", Names: []string{"/app"}, Image: "nexus.example.com/team/app:latest", ImageID: "sha256:aaa", State: container.StateRunning},
+		},
+		images: map[string]image.InspectResponse{
+			"sha256:aaa": {RepoDigests: []string{"nexus.example.com/team/app@" + digest}},
+		},
+	}
+
+	checker := NewChecker(NewDockerSource(cli, log),
+		WithDefaultRegistryClient(&staticRegistry{}),
+		WithDigestClient(staticDigests{}),
+		WithRegistryDigestClient("nexus.example.com", staticDigests{"nexus.example.com/team/app:latest": digest}),
+		WithLogger(log),
+	)
+	statuses, err := checker.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() unexpected error = %v", err)
+	}
+
+	if statuses[0].Status != types.StatusUpToDate {
+		t.Errorf("Expected the registry digest client to be used, got %+v", statuses[0])
+	}
+}
+
+func TestChecker_FloatingTagDigest(t *testing.T) {
+	const (
+		oldDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		newDigest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+
+	log := zap.NewNop().Sugar()
+	cli := &fakeDockerClient{
+		containers: []container.Summary{
+			{ID: "1", Names: []string{"/cache"}, Image: "redis:7", ImageID: "sha256:aaa", State: container.StateRunning},
+			{ID: "2", Names: []string{"/web"}, Image: "nginx:1.25", ImageID: "sha256:bbb", State: container.StateRunning},
+			{ID: "3", Names: []string{"/db"}, Image: "postgres:16", ImageID: "sha256:ccc", State: container.StateRunning},
+		},
+		images: map[string]image.InspectResponse{
+			"sha256:aaa": {RepoDigests: []string{"redis@" + oldDigest}},
+			"sha256:bbb": {RepoDigests: []string{"nginx@" + oldDigest}},
+			"sha256:ccc": {RepoDigests: []string{"postgres@" + newDigest}},
+		},
+	}
+	digests := staticDigests{"redis:7": newDigest, "nginx:1.25": newDigest, "postgres:16": newDigest}
+	dockerHub := &staticRegistry{tags: map[string][]string{
+		"library/redis":    {"6", "7"},
+		"library/nginx":    {"1.25", "1.27"},
+		"library/postgres": {"15", "16"},
+	}}
+
+	checker := NewChecker(NewDockerSource(cli, log),
+		WithRegistryClient("docker.io", dockerHub),
+		WithDigestClient(digests),
+		WithLogger(log),
+	)
+	statuses, err := checker.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() unexpected error = %v", err)
+	}
+
+	if statuses[0].Status != types.StatusNewDigest || statuses[0].LatestDigest != newDigest || statuses[0].CurrentDigest != oldDigest {
+		t.Errorf("Expected a newer image re-pushed under redis:7, got %+v", statuses[0])
+	}
+	if statuses[1].Status != types.StatusUpdateAvailable || statuses[1].LatestAvailableTag != "1.27" {
+		t.Errorf("Expected the newer nginx version to take precedence over its digest, got %+v", statuses[1])
+	}
+	if statuses[2].Status != types.StatusUpToDate {
+		t.Errorf("Expected postgres:16 to be up to date, got %+v", statuses[2])
+	}
+}
@@ -5,20 +5,29 @@ import (
 	"fmt"
 
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
 	"go.uber.org/zap"
 )
 
-// getRunningContainerImages connects to the Docker daemon and returns a list of running containers.
-func GetRunningContainerImages(ctx context.Context, log *zap.SugaredLogger) ([]container.Summary, error) {
-	log.Info("Connecting to Docker daemon")
+// DockerAPI is the subset of the Docker Engine API client used by Chuck.
+// It is satisfied by *client.Client and can be replaced by a fake in tests.
+type DockerAPI interface {
+	ContainerList(ctx context.Context, options container.ListOptions) ([]container.Summary, error)
+	ImageInspect(ctx context.Context, imageID string, inspectOpts ...client.ImageInspectOption) (image.InspectResponse, error)
+}
+
+// NewDockerClient creates a client for the Docker daemon configured in the environment (DOCKER_HOST, DOCKER_TLS_VERIFY, ...)
+func NewDockerClient() (*client.Client, error) {
 	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
 	if err != nil {
 		return nil, fmt.Errorf("error creating Docker client: %w", err)
 	}
-	defer cli.Close()
-	log.Info("Successfully connected to Docker daemon")
+	return cli, nil
+}
 
+// GetRunningContainerImages returns the list of running containers known to the Docker daemon behind cli.
+func GetRunningContainerImages(ctx context.Context, cli DockerAPI, log *zap.SugaredLogger) ([]container.Summary, error) {
 	log.Info("Listing running containers")
 	containers, err := cli.ContainerList(ctx, container.ListOptions{
 		All: false,
@@ -28,3 +37,22 @@ func GetRunningContainerImages(ctx context.Context, log *zap.SugaredLogger) ([]c
 	}
 	return containers, nil
 }
+
+// DockerSource is a ContainerSource listing the running containers of a Docker daemon
+type DockerSource struct {
+	cli    DockerAPI
+	logger *zap.SugaredLogger
+}
+
+// NewDockerSource creates a DockerSource querying the daemon through cli
+func NewDockerSource(cli DockerAPI, logger *zap.SugaredLogger) *DockerSource {
+	return &DockerSource{
+		cli:    cli,
+		logger: logger,
+	}
+}
+
+// ListContainers returns the running containers
+func (s *DockerSource) ListContainers(ctx context.Context) ([]container.Summary, error) {
+	return GetRunningContainerImages(ctx, s.cli, s.logger)
+}
@@ -2,6 +2,8 @@ package core
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"slices"
@@ -11,6 +13,7 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -108,7 +111,7 @@ func TestGetRunningContainerImages(t *testing.T) {
 	// Test without running containers
 	// The user is responsible to run this code on a clean docker host with no running containers (yet)
 	t.Run("NoRunningContainers", func(t *testing.T) {
-		containers, err := GetRunningContainerImages(ctx, log)
+		containers, err := GetRunningContainerImages(ctx, cli, log)
 		if err != nil {
 			t.Fatalf("Expected no error when listing 0 containers, got: %v", err)
 		}
@@ -123,7 +126,7 @@ func TestGetRunningContainerImages(t *testing.T) {
 		imageName := "nginx:1.25"
 		_ = createAndStartContainer(t, ctx, cli, imageName, containerName)
 
-		containers, err := GetRunningContainerImages(ctx, log)
+		containers, err := GetRunningContainerImages(ctx, cli, log)
 		if err != nil {
 			t.Fatalf("Expected no error when listing containers, got %v", err)
 		}
@@ -157,7 +160,7 @@ func TestGetRunningContainerImages(t *testing.T) {
 			_ = createAndStartContainer(t, ctx, cli, testContainer.image, testContainer.container)
 		}
 
-		containers, err := GetRunningContainerImages(ctx, log)
+		containers, err := GetRunningContainerImages(ctx, cli, log)
 		if err != nil {
 			t.Fatalf("Expected no error when listing containers, got %v", err)
 		}
@@ -174,9 +177,94 @@ func TestGetRunningContainerImages(t *testing.T) {
 		_ = os.Setenv("DOCKER_HOST", "tcp://127.0.0.1:63001")
 		defer os.Setenv("DOCKER_HOST", originalDockerHost)
 
-		_, err := GetRunningContainerImages(ctx, log)
+		// The client connects lazily, so creating it succeeds and listing containers fails
+		invalidCli, err := NewDockerClient()
+		require.NoError(t, err)
+		defer invalidCli.Close()
+
+		_, err = GetRunningContainerImages(ctx, invalidCli, log)
 		if err == nil {
 			t.Error("Expected an error in case of docker client creation failure, but got none")
 		}
 	})
 }
+
+// fakeDockerClient is an in-memory DockerAPI used to test the scan path without a Docker daemon
+type fakeDockerClient struct {
+	containers []container.Summary
+	images     map[string]image.InspectResponse
+	listErr    error
+}
+
+func (f *fakeDockerClient) ContainerList(_ context.Context, options container.ListOptions) ([]container.Summary, error) {
+	if f.listErr != nil {
+		return nil, f.listErr
+	}
+
+	var running []container.Summary
+	for _, cnt := range f.containers {
+		if options.All || cnt.State == container.StateRunning {
+			running = append(running, cnt)
+		}
+	}
+	return running, nil
+}
+
+func (f *fakeDockerClient) ImageInspect(_ context.Context, imageID string, _ ...client.ImageInspectOption) (image.InspectResponse, error) {
+	inspect, ok := f.images[imageID]
+	if !ok {
+		return image.InspectResponse{}, fmt.Errorf("no such image: %s", imageID)
+	}
+	return inspect, nil
+}
+
+func TestGetRunningContainerImages_Fake(t *testing.T) {
+	log := zap.NewNop().Sugar()
+	cli := &fakeDockerClient{containers: []container.Summary{
+		{ID: "1", Names: []string{"/web"}, Image: "nginx:1.25", State: container.StateRunning},
+		{ID: "2", Names: []string{"/old"}, Image: "nginx:1.21", State: container.StateExited},
+		{ID: "3", Names: []string{"/cache"}, Image: "redis:7.2.0", State: container.StateRunning},
+	}}
+
+	containers, err := GetRunningContainerImages(context.Background(), cli, log)
+	if err != nil {
+		t.Fatalf("Expected no error when listing containers, got %v", err)
+	}
+	if len(containers) != 2 {
+		t.Errorf("Expected 2 running containers, got %d", len(containers))
+	}
+
+	cli.listErr = errors.New("daemon unreachable")
+	if _, err := GetRunningContainerImages(context.Background(), cli, log); err == nil {
+		t.Error("Expected an error when the daemon cannot list containers, but got none")
+	}
+}
+
+// TestDockerSource_Check runs the whole scan path against a fake daemon and registry
+func TestDockerSource_Check(t *testing.T) {
+	log := zap.NewNop().Sugar()
+	cli := &fakeDockerClient{containers: []container.Summary{
+		{ID: "1", Names: []string{"/web"}, Image: "nginx:1.25", State: container.StateRunning},
+		{ID: "2", Names: []string{"/cache"}, Image: "redis:7.2.0", State: container.StateRunning},
+	}}
+	dockerHub := &staticRegistry{tags: map[string][]string{
+		"library/nginx": {"1.25", "1.27"},
+		"library/redis": {"7.2.0"},
+	}}
+
+	checker := NewChecker(NewDockerSource(cli, log), WithRegistryClient("docker.io", dockerHub), WithLogger(log))
+	statuses, err := checker.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() unexpected error = %v", err)
+	}
+
+	if len(statuses) != 2 {
+		t.Fatalf("Expected 2 statuses, got %d", len(statuses))
+	}
+	if !statuses[0].UpdateAvailable || statuses[0].LatestAvailableTag != "1.27" {
+		t.Errorf("Expected web to be upgradable to 1.27, got %+v", statuses[0])
+	}
+	if statuses[1].UpdateAvailable {
+		t.Errorf("Expected cache to be up to date, got %+v", statuses[1])
+	}
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"context"
+	"sync"
+
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultParallelism is the default number of concurrent tag listings
+	DefaultParallelism = 4
+	// DefaultRegistryParallelism is the default number of concurrent tag listings against the same registry
+	DefaultRegistryParallelism = 2
+)
+
+// RegistryClient defines the capabilities of a generic client for container registries
+type RegistryClient interface {
+	// GetTags fetches all available tags for a given image from the registry
+	GetTags(ctx context.Context, image types.Image) ([]string, error)
+}
+
+// TagInfoLister is implemented by registry clients returning the metadata of each tag
+// (e.g. its last modification), which is then reported along with the update status
+type TagInfoLister interface {
+	// GetTagInfos fetches all available tags for a given image along with their metadata
+	GetTagInfos(ctx context.Context, image types.Image) ([]types.TagInfo, error)
+}
+
+// TagJob describes the tag listing of a repository
+type TagJob struct {
+	Key    string         // Unique key of the repository (e.g. docker.io/library/nginx)
+	Image  types.Image    // Any image of the repository
+	Client RegistryClient // Client of the image registry
+}
+
+// TagResult holds the outcome of a tag listing
+type TagResult struct {
+	Tags  []string
+	Infos []types.TagInfo // Metadata of the tags, when the registry client is a TagInfoLister
+	Err   error
+}
+
+// TagFetcher lists the tags of many repositories concurrently through a bounded worker pool
+type TagFetcher struct {
+	parallelism         int
+	registryParallelism int
+	logger              *zap.SugaredLogger
+}
+
+// NewTagFetcher creates a TagFetcher running at most parallelism listings at once,
+// and at most registryParallelism against the same registry
+func NewTagFetcher(parallelism, registryParallelism int, logger *zap.SugaredLogger) *TagFetcher {
+	return &TagFetcher{
+		parallelism:         max(parallelism, 1),
+		registryParallelism: max(registryParallelism, 1),
+		logger:              logger,
+	}
+}
+
+// FetchAll runs the given jobs and returns their results by repository key.
+// Jobs sharing the same key are fetched only once.
+func (f *TagFetcher) FetchAll(ctx context.Context, jobs []TagJob) map[string]TagResult {
+	// Deduplicate jobs while keeping their order
+	seen := make(map[string]bool)
+	var uniqueJobs []TagJob
+	registryLimits := make(map[string]chan struct{})
+	for _, job := range jobs {
+		if seen[job.Key] {
+			continue
+		}
+		seen[job.Key] = true
+		uniqueJobs = append(uniqueJobs, job)
+
+		if _, ok := registryLimits[job.Image.Registry]; !ok {
+			registryLimits[job.Image.Registry] = make(chan struct{}, f.registryParallelism)
+		}
+	}
+
+	results := make(map[string]TagResult, len(uniqueJobs))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	queue := make(chan TagJob)
+	for range min(f.parallelism, len(uniqueJobs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				result := f.fetch(ctx, job, registryLimits[job.Image.Registry])
+
+				mu.Lock()
+				results[job.Key] = result
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, job := range uniqueJobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+
+	return results
+}
+
+// fetch runs a single job, holding a slot of the registry limit
+func (f *TagFetcher) fetch(ctx context.Context, job TagJob, registryLimit chan struct{}) TagResult {
+	select {
+	case registryLimit <- struct{}{}:
+		defer func() { <-registryLimit }()
+	case <-ctx.Done():
+		return TagResult{Err: ctx.Err()}
+	}
+
+	f.logger.Debugf("fetching tags for image %s", job.Key)
+	if lister, ok := job.Client.(TagInfoLister); ok {
+		infos, err := lister.GetTagInfos(ctx, job.Image)
+		if err != nil {
+			return TagResult{Err: err}
+		}
+
+		tags := make([]string, len(infos))
+		for i, info := range infos {
+			tags[i] = info.Name
+		}
+		f.logger.Debugf("Found %d tags for image %s", len(tags), job.Key)
+		return TagResult{Tags: tags, Infos: infos}
+	}
+
+	tags, err := job.Client.GetTags(ctx, job.Image)
+	if err != nil {
+		return TagResult{Err: err}
+	}
+
+	f.logger.Debugf("Found %d tags for image %s", len(tags), job.Key)
+	return TagResult{Tags: tags}
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"go.uber.org/zap"
+)
+
+// countingClient records calls and the peak number of concurrent listings
+type countingClient struct {
+	mu      sync.Mutex
+	calls   map[string]int
+	running int
+	peak    int
+}
+
+func (c *countingClient) GetTags(ctx context.Context, image types.Image) ([]string, error) {
+	c.mu.Lock()
+	c.calls[image.Name]++
+	c.running++
+	c.peak = max(c.peak, c.running)
+	c.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	c.mu.Lock()
+	c.running--
+	c.mu.Unlock()
+
+	if image.Name == "broken" {
+		return nil, errors.New("registry error")
+	}
+	return []string{image.Name + "-1.0"}, nil
+}
+
+func TestTagFetcher_FetchAll(t *testing.T) {
+	client := &countingClient{calls: make(map[string]int)}
+
+	var jobs []TagJob
+	for i := range 10 {
+		image := types.Image{Registry: "docker.io", Namespace: "library", Name: fmt.Sprintf("app%d", i%5)}
+		jobs = append(jobs, TagJob{Key: "docker.io/library/" + image.Name, Image: image, Client: client})
+	}
+	broken := types.Image{Registry: "docker.io", Namespace: "library", Name: "broken"}
+	jobs = append(jobs, TagJob{Key: "docker.io/library/broken", Image: broken, Client: client})
+
+	fetcher := NewTagFetcher(8, 2, zap.NewNop().Sugar())
+	results := fetcher.FetchAll(context.Background(), jobs)
+
+	if len(results) != 6 {
+		t.Fatalf("Expected 6 results, got %d", len(results))
+	}
+	for i := range 5 {
+		name := fmt.Sprintf("app%d", i)
+		result := results["docker.io/library/"+name]
+		if result.Err != nil || !reflect.DeepEqual(result.Tags, []string{name + "-1.0"}) {
+			t.Errorf("Unexpected result for %s: %+v", name, result)
+		}
+		if client.calls[name] != 1 {
+			t.Errorf("Expected tags of %s to be fetched once, got %d", name, client.calls[name])
+		}
+	}
+	if results["docker.io/library/broken"].Err == nil {
+		t.Errorf("Expected an error for the broken repository")
+	}
+
+	// All jobs target the same registry, so its limit applies
+	if client.peak > 2 {
+		t.Errorf("Expected at most 2 concurrent listings on the registry, got %d", client.peak)
+	}
+}
+
+func TestTagFetcher_Parallelism(t *testing.T) {
+	client := &countingClient{calls: make(map[string]int)}
+
+	var jobs []TagJob
+	for i := range 6 {
+		image := types.Image{Registry: fmt.Sprintf("registry%d.example.com", i), Name: "app"}
+		jobs = append(jobs, TagJob{Key: image.Registry + "/app", Image: image, Client: client})
+	}
+
+	fetcher := NewTagFetcher(3, 2, zap.NewNop().Sugar())
+	results := fetcher.FetchAll(context.Background(), jobs)
+
+	if len(results) != 6 {
+		t.Fatalf("Expected 6 results, got %d", len(results))
+	}
+	if client.peak > 3 {
+		t.Errorf("Expected at most 3 concurrent listings, got %d", client.peak)
+	}
+}
+
+func TestTagFetcher_Empty(t *testing.T) {
+	results := NewTagFetcher(0, 0, zap.NewNop().Sugar()).FetchAll(context.Background(), nil)
+	if len(results) != 0 {
+		t.Errorf("Expected no results, got %d", len(results))
+	}
+}
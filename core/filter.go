@@ -0,0 +1,159 @@
+package core
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+// Filter fields supported by filter rules
+const (
+	FilterFieldName     = "name"     // Container name
+	FilterFieldImage    = "image"    // Image repository (e.g. library/nginx) or name (e.g. nginx)
+	FilterFieldRegistry = "registry" // Image registry (e.g. docker.io)
+	FilterFieldTag      = "tag"      // Image tag
+	FilterFieldLabel    = "label:"   // Docker label, followed by its key (e.g. label:com.example.role)
+)
+
+// FilterRule matches containers on a single field.
+// Rules are written as field=pattern, where pattern is a glob (e.g. name=sidecar-*)
+// or a regular expression when prefixed by ~ (e.g. tag=~^v?1\.).
+type FilterRule struct {
+	raw      string
+	field    string
+	labelKey string
+	glob     string
+	regex    *regexp.Regexp
+}
+
+// ParseFilterRule parses a rule in the field=pattern format
+func ParseFilterRule(rule string) (FilterRule, error) {
+	field, pattern, found := strings.Cut(rule, "=")
+	if !found || field == "" || pattern == "" {
+		return FilterRule{}, fmt.Errorf("invalid filter rule %q: expected field=pattern", rule)
+	}
+
+	parsed := FilterRule{raw: rule, field: field}
+
+	switch {
+	case field == FilterFieldName, field == FilterFieldImage, field == FilterFieldRegistry, field == FilterFieldTag:
+	case strings.HasPrefix(field, FilterFieldLabel) && len(field) > len(FilterFieldLabel):
+		parsed.field = FilterFieldLabel
+		parsed.labelKey = strings.TrimPrefix(field, FilterFieldLabel)
+	default:
+		return FilterRule{}, fmt.Errorf("invalid filter rule %q: unknown field %q (expected name, image, registry, tag or label:<key>)", rule, field)
+	}
+
+	if expression, isRegex := strings.CutPrefix(pattern, "~"); isRegex {
+		regex, err := regexp.Compile(expression)
+		if err != nil {
+			return FilterRule{}, fmt.Errorf("invalid filter rule %q: %w", rule, err)
+		}
+		parsed.regex = regex
+		return parsed, nil
+	}
+
+	if _, err := path.Match(pattern, ""); err != nil {
+		return FilterRule{}, fmt.Errorf("invalid filter rule %q: %w", rule, err)
+	}
+	parsed.glob = pattern
+	return parsed, nil
+}
+
+// String returns the rule as written
+func (r FilterRule) String() string {
+	return r.raw
+}
+
+// Matches reports whether the container and the image it runs match the rule
+func (r FilterRule) Matches(cnt container.Summary, containerName string, image types.Image) bool {
+	switch r.field {
+	case FilterFieldName:
+		return r.matchValue(containerName)
+	case FilterFieldImage:
+		repository := image.Name
+		if image.Namespace != "" && image.Namespace != "." {
+			repository = image.Namespace + "/" + image.Name
+		}
+		return r.matchValue(repository) || r.matchValue(image.Name)
+	case FilterFieldRegistry:
+		return r.matchValue(image.Registry)
+	case FilterFieldTag:
+		return r.matchValue(image.Tag)
+	case FilterFieldLabel:
+		value, ok := cnt.Labels[r.labelKey]
+		return ok && r.matchValue(value)
+	}
+	return false
+}
+
+// matchValue matches a single value against the pattern of the rule
+func (r FilterRule) matchValue(value string) bool {
+	if r.regex != nil {
+		return r.regex.MatchString(value)
+	}
+	matched, _ := path.Match(r.glob, value)
+	return matched
+}
+
+// Filter selects the containers to check.
+// When include rules are set, a container must match at least one of them;
+// a container matching any exclude rule is skipped.
+type Filter struct {
+	include []FilterRule
+	exclude []FilterRule
+}
+
+// NewFilter parses the include and exclude rules
+func NewFilter(include, exclude []string) (*Filter, error) {
+	filter := &Filter{}
+
+	for _, rule := range include {
+		parsed, err := ParseFilterRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		filter.include = append(filter.include, parsed)
+	}
+	for _, rule := range exclude {
+		parsed, err := ParseFilterRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		filter.exclude = append(filter.exclude, parsed)
+	}
+
+	return filter, nil
+}
+
+// Skip reports whether a container must be skipped, along with the reason
+func (f *Filter) Skip(cnt container.Summary, containerName string, image types.Image) (bool, string) {
+	if f == nil {
+		return false, ""
+	}
+
+	if len(f.include) > 0 {
+		included := false
+		for _, rule := range f.include {
+			if rule.Matches(cnt, containerName, image) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return true, "not matching any include rule"
+		}
+	}
+
+	for _, rule := range f.exclude {
+		if rule.Matches(cnt, containerName, image) {
+			return true, fmt.Sprintf("matching exclude rule %s", rule)
+		}
+	}
+
+	return false, ""
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestParseFilterRule_Invalid(t *testing.T) {
+	rules := []string{
+		"",
+		"name",
+		"name=",
+		"=web",
+		"owner=me",
+		"label:=x",
+		"name=[",
+		"tag=~^(",
+	}
+	for _, rule := range rules {
+		if _, err := ParseFilterRule(rule); err == nil {
+			t.Errorf("ParseFilterRule(%q) expected an error", rule)
+		}
+	}
+}
+
+func TestFilterRule_Matches(t *testing.T) {
+	cnt := container.Summary{
+		Image:  "ghcr.io/acme/envoy:v1.30.1",
+		Labels: map[string]string{"com.example.role": "sidecar"},
+	}
+	image, err := ParseImageName(cnt.Image)
+	if err != nil {
+		t.Fatalf("ParseImageName() unexpected error = %v", err)
+	}
+
+	tests := []struct {
+		rule     string
+		expected bool
+	}{
+		{"name=proxy-*", true},
+		{"name=web-*", false},
+		{"name=~^proxy-[0-9]+$", true},
+		{"image=acme/envoy", true},
+		{"image=envoy", true},
+		{"image=acme/*", true},
+		{"image=library/*", false},
+		{"registry=ghcr.io", true},
+		{"registry=*.example.com", false},
+		{"tag=v1.*", true},
+		{"tag=~^1\\.", false},
+		{"label:com.example.role=sidecar", true},
+		{"label:com.example.role=~^side", true},
+		{"label:com.example.role=app", false},
+		{"label:com.example.missing=*", false},
+	}
+
+	for _, tt := range tests {
+		rule, err := ParseFilterRule(tt.rule)
+		if err != nil {
+			t.Fatalf("ParseFilterRule(%q) unexpected error = %v", tt.rule, err)
+		}
+		if got := rule.Matches(cnt, "proxy-1", image); got != tt.expected {
+			t.Errorf("%q.Matches() = %v, expected %v", tt.rule, got, tt.expected)
+		}
+	}
+}
+
+func TestFilter_Skip(t *testing.T) {
+	filter, err := NewFilter([]string{"registry=docker.io", "label:chuck.check=true"}, []string{"name=*-sidecar"})
+	if err != nil {
+		t.Fatalf("NewFilter() unexpected error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		cnt      container.Summary
+		expected bool
+	}{
+		{name: "web", cnt: container.Summary{Image: "nginx:1.25"}, expected: false},
+		{name: "web-sidecar", cnt: container.Summary{Image: "nginx:1.25"}, expected: true},
+		{name: "internal", cnt: container.Summary{Image: "registry.example.com/team/app:1.0.0"}, expected: true},
+		{name: "labelled", cnt: container.Summary{Image: "registry.example.com/team/app:1.0.0", Labels: map[string]string{"chuck.check": "true"}}, expected: false},
+	}
+
+	for _, tt := range tests {
+		image, _ := ParseImageName(tt.cnt.Image)
+		skip, reason := filter.Skip(tt.cnt, tt.name, image)
+		if skip != tt.expected {
+			t.Errorf("Skip(%s) = %v (%s), expected %v", tt.name, skip, reason, tt.expected)
+		}
+		if skip && reason == "" {
+			t.Errorf("Skip(%s) expected a reason", tt.name)
+		}
+	}
+
+	var noFilter *Filter
+	if skip, _ := noFilter.Skip(container.Summary{}, "web", types.Image{}); skip {
+		t.Error("A nil filter must not skip containers")
+	}
+}
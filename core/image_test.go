@@ -206,6 +206,18 @@ func TestParseImageName(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:  "GitLab registry - nested project path",
+			input: "registry.gitlab.example.com/group/subgroup/project/app:2.0.1",
+			expected: types.Image{
+				Raw:       "registry.gitlab.example.com/group/subgroup/project/app:2.0.1",
+				Registry:  "registry.gitlab.example.com",
+				Namespace: "group/subgroup/project",
+				Name:      "app",
+				Tag:       "2.0.1",
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tc := range testCases {
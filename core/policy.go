@@ -0,0 +1,188 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Docker labels controlling the update check of a container
+const (
+	LabelEnable         = "chuck.enable"          // false disables the check of the container
+	LabelPolicy         = "chuck.policy"          // Highest version change reported (patch, minor, any)
+	LabelTagRegex       = "chuck.tag-regex"       // Regular expression candidate tags must match
+	LabelIgnoreVersions = "chuck.ignore-versions" // Comma-separated semver constraints of versions to ignore (e.g. 1.27.x, >=2)
+	LabelPrerelease     = "chuck.prerelease"      // Pre-release policy (exclude, auto, include)
+)
+
+// PolicyLevel is the highest version change reported as an update
+type PolicyLevel string
+
+const (
+	PolicyPatch PolicyLevel = "patch" // Only newer patches of the same major.minor
+	PolicyMinor PolicyLevel = "minor" // Only newer minors and patches of the same major
+	PolicyAny   PolicyLevel = "any"   // Any newer version, including newer majors
+)
+
+// policyMajorAlias is accepted as an alias of PolicyAny, as newer majors are the highest change
+const policyMajorAlias = "major"
+
+// PrereleasePolicy controls whether pre-release tags (e.g. 1.26.0-rc1) are suggested
+type PrereleasePolicy string
+
+const (
+	PrereleaseExclude PrereleasePolicy = "exclude" // Never suggest pre-releases
+	PrereleaseAuto    PrereleasePolicy = "auto"    // Suggest pre-releases only to containers running a pre-release
+	PrereleaseInclude PrereleasePolicy = "include" // Always suggest pre-releases
+)
+
+// UpdatePolicy controls which tags are considered as updates of a container
+type UpdatePolicy struct {
+	Level          PolicyLevel
+	Prerelease     PrereleasePolicy
+	TagRegex       *regexp.Regexp
+	IgnoreVersions []*semver.Constraints
+	// IgnoreMetadata lists patterns removed from variant suffixes before comparing them,
+	// for build metadata such as the -r5 revision of 1.25.3-debian-12-r5
+	IgnoreMetadata []*regexp.Regexp
+}
+
+// DefaultUpdatePolicy reports any newer stable version
+func DefaultUpdatePolicy() UpdatePolicy {
+	return UpdatePolicy{Level: PolicyAny, Prerelease: PrereleaseExclude}
+}
+
+// ParsePrereleasePolicy parses a pre-release policy, case insensitively
+func ParsePrereleasePolicy(policy string) (PrereleasePolicy, error) {
+	switch parsed := PrereleasePolicy(strings.ToLower(strings.TrimSpace(policy))); parsed {
+	case PrereleaseExclude, PrereleaseAuto, PrereleaseInclude:
+		return parsed, nil
+	}
+	return "", fmt.Errorf("invalid pre-release policy %q (expected exclude, auto or include)", policy)
+}
+
+// ParseMetadataPatterns compiles the patterns matching build metadata in variant suffixes
+func ParseMetadataPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid build metadata pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, regex)
+	}
+	return compiled, nil
+}
+
+// ParsePolicyLevel parses a policy level, case insensitively. "major" is parsed as PolicyAny.
+func ParsePolicyLevel(level string) (PolicyLevel, error) {
+	switch parsed := PolicyLevel(strings.ToLower(strings.TrimSpace(level))); parsed {
+	case PolicyPatch, PolicyMinor, PolicyAny:
+		return parsed, nil
+	case policyMajorAlias:
+		return PolicyAny, nil
+	}
+	return "", fmt.Errorf("invalid policy %q (expected patch, minor or any)", level)
+}
+
+// PolicyFromLabels reads the update policy declared by the labels of a container.
+// enabled is false when the container opted out of the check with chuck.enable=false.
+func PolicyFromLabels(labels map[string]string) (UpdatePolicy, bool, error) {
+	return DefaultUpdatePolicy().WithLabels(labels)
+}
+
+// WithLabels returns the policy overridden by the labels of a container.
+// enabled is false when the container opted out of the check with chuck.enable=false.
+func (p UpdatePolicy) WithLabels(labels map[string]string) (policy UpdatePolicy, enabled bool, err error) {
+	policy = p
+
+	if value, ok := labels[LabelEnable]; ok {
+		enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return policy, true, fmt.Errorf("invalid value %q for label %s: expected true or false", value, LabelEnable)
+		}
+		if !enabled {
+			return policy, false, nil
+		}
+	}
+
+	if value, ok := labels[LabelPolicy]; ok {
+		level, err := ParsePolicyLevel(value)
+		if err != nil {
+			return policy, true, fmt.Errorf("label %s: %w", LabelPolicy, err)
+		}
+		policy.Level = level
+	}
+
+	if value, ok := labels[LabelPrerelease]; ok {
+		prerelease, err := ParsePrereleasePolicy(value)
+		if err != nil {
+			return policy, true, fmt.Errorf("label %s: %w", LabelPrerelease, err)
+		}
+		policy.Prerelease = prerelease
+	}
+
+	if value, ok := labels[LabelTagRegex]; ok && value != "" {
+		regex, err := regexp.Compile(value)
+		if err != nil {
+			return policy, true, fmt.Errorf("invalid regular expression for label %s: %w", LabelTagRegex, err)
+		}
+		policy.TagRegex = regex
+	}
+
+	if value, ok := labels[LabelIgnoreVersions]; ok {
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			constraint, err := semver.NewConstraint(item)
+			if err != nil {
+				return policy, true, fmt.Errorf("invalid version constraint %q for label %s: %w", item, LabelIgnoreVersions, err)
+			}
+			policy.IgnoreVersions = append(policy.IgnoreVersions, constraint)
+		}
+	}
+
+	return policy, true, nil
+}
+
+// allows reports whether a candidate tag is acceptable under the tag filters of the policy.
+// The policy level does not apply here: it only selects which candidate is reported as the update.
+func (p UpdatePolicy) allows(current, candidate Tag) bool {
+	if p.TagRegex != nil && !p.TagRegex.MatchString(candidate.Raw) {
+		return false
+	}
+
+	if candidate.Prerelease != "" {
+		switch p.Prerelease {
+		case PrereleaseInclude:
+		case PrereleaseAuto:
+			if current.Prerelease == "" {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	for _, constraint := range p.IgnoreVersions {
+		if constraint.Check(candidate.Version) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// stripMetadata removes the build metadata matched by IgnoreMetadata from the variant of a tag
+func (p UpdatePolicy) stripMetadata(tag Tag) Tag {
+	for _, pattern := range p.IgnoreMetadata {
+		tag.Variant = pattern.ReplaceAllString(tag.Variant, "")
+	}
+	tag.Variant = strings.Trim(tag.Variant, "-")
+	return tag
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestPolicyFromLabels(t *testing.T) {
+	testCases := []struct {
+		name            string
+		labels          map[string]string
+		expectedLevel   PolicyLevel
+		expectedEnabled bool
+		wantErr         bool
+	}{
+		{name: "No labels", labels: nil, expectedLevel: PolicyAny, expectedEnabled: true},
+		{name: "Disabled", labels: map[string]string{LabelEnable: "false"}, expectedLevel: PolicyAny, expectedEnabled: false},
+		{name: "Explicitly enabled", labels: map[string]string{LabelEnable: "true", LabelPolicy: "Minor"}, expectedLevel: PolicyMinor, expectedEnabled: true},
+		{name: "Patch policy", labels: map[string]string{LabelPolicy: "patch"}, expectedLevel: PolicyPatch, expectedEnabled: true},
+		{name: "Major alias", labels: map[string]string{LabelPolicy: "Major"}, expectedLevel: PolicyAny, expectedEnabled: true},
+		{name: "Invalid enable", labels: map[string]string{LabelEnable: "nope"}, expectedEnabled: true, wantErr: true},
+		{name: "Invalid policy", labels: map[string]string{LabelPolicy: "yolo"}, expectedEnabled: true, wantErr: true},
+		{name: "Invalid prerelease", labels: map[string]string{LabelPrerelease: "sometimes"}, expectedEnabled: true, wantErr: true},
+		{name: "Invalid regex", labels: map[string]string{LabelTagRegex: "^("}, expectedEnabled: true, wantErr: true},
+		{name: "Invalid ignore versions", labels: map[string]string{LabelIgnoreVersions: "1.x, not-a-version"}, expectedEnabled: true, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, enabled, err := PolicyFromLabels(tc.labels)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("PolicyFromLabels() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if enabled != tc.expectedEnabled {
+				t.Errorf("PolicyFromLabels() enabled = %v, expected %v", enabled, tc.expectedEnabled)
+			}
+			if !tc.wantErr && policy.Level != tc.expectedLevel {
+				t.Errorf("PolicyFromLabels() level = %q, expected %q", policy.Level, tc.expectedLevel)
+			}
+		})
+	}
+}
+
+func TestFindLatestUpdate_Policy(t *testing.T) {
+	available := []string{"1.24.0", "1.25.0", "1.25.3", "1.26.1", "1.27.0", "2.0.0", "2.1.0", "latest"}
+
+	testCases := []struct {
+		name     string
+		current  string
+		labels   map[string]string
+		expected string
+	}{
+		{name: "Default", current: "1.25.0", expected: "2.1.0"},
+		{name: "Patch", current: "1.25.0", labels: map[string]string{LabelPolicy: "patch"}, expected: "1.25.3"},
+		{name: "Minor", current: "1.25.0", labels: map[string]string{LabelPolicy: "minor"}, expected: "1.27.0"},
+		{name: "Ignored versions", current: "1.25.0", labels: map[string]string{LabelIgnoreVersions: ">=2, 1.27.0"}, expected: "1.26.1"},
+		{name: "Tag regex", current: "1.25.0", labels: map[string]string{LabelTagRegex: `^1\.2[56]\.`}, expected: "1.26.1"},
+		{name: "No update within policy", current: "1.25.3", labels: map[string]string{LabelPolicy: "patch"}, expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, _, err := PolicyFromLabels(tc.labels)
+			if err != nil {
+				t.Fatalf("PolicyFromLabels() unexpected error = %v", err)
+			}
+
+			latest, found, err := FindLatestUpdate(tc.current, available, policy)
+			if err != nil {
+				t.Fatalf("FindLatestUpdate() unexpected error = %v", err)
+			}
+			if latest != tc.expected || found != (tc.expected != "") {
+				t.Errorf("FindLatestUpdate() = (%q, %v), expected %q", latest, found, tc.expected)
+			}
+		})
+	}
+}
+
+func TestFindUpdates(t *testing.T) {
+	available := []string{"13.2.0", "13.2.4", "13.9.0", "14.0.0", "16.1.0", "17.0.0", "17.2.0"}
+
+	testCases := []struct {
+		name     string
+		current  string
+		level    PolicyLevel
+		expected Updates
+	}{
+		{
+			name:     "Any",
+			current:  "13.2.0",
+			level:    PolicyAny,
+			expected: Updates{Patch: "13.2.4", Minor: "13.9.0", Major: "17.2.0", Latest: "17.2.0"},
+		},
+		{
+			name:     "Minor",
+			current:  "13.2.0",
+			level:    PolicyMinor,
+			expected: Updates{Patch: "13.2.4", Minor: "13.9.0", Major: "17.2.0", Latest: "13.9.0"},
+		},
+		{
+			name:     "Patch",
+			current:  "13.2.0",
+			level:    PolicyPatch,
+			expected: Updates{Patch: "13.2.4", Minor: "13.9.0", Major: "17.2.0", Latest: "13.2.4"},
+		},
+		{
+			name:     "No update within major",
+			current:  "13.9.0",
+			level:    PolicyMinor,
+			expected: Updates{Major: "17.2.0"},
+		},
+		{
+			name:     "Latest version",
+			current:  "17.2.0",
+			level:    PolicyAny,
+			expected: Updates{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			updates, err := FindUpdates(tc.current, available, UpdatePolicy{Level: tc.level})
+			if err != nil {
+				t.Fatalf("FindUpdates() unexpected error = %v", err)
+			}
+			if updates != tc.expected {
+				t.Errorf("FindUpdates() = %+v, expected %+v", updates, tc.expected)
+			}
+		})
+	}
+}
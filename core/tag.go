@@ -0,0 +1,63 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// prereleasePattern matches the suffix parts identifying a pre-release: numeric identifiers as defined
+// by semver (e.g. 0.1) and known keywords (e.g. rc1, beta.2, nightly, m1).
+// Other alphanumeric identifiers (e.g. x.7) are deliberately left to variants, as they cannot be told
+// apart from variant names such as alpine or r5.
+var prereleasePattern = regexp.MustCompile(`(?i)^(0|[1-9][0-9]*|alpha|beta|rc|pre|preview|dev|nightly|snapshot|canary|m)(\.?[0-9]+)*$`)
+
+// Tag is an image tag split into its version and variant suffix (e.g. 3.12-slim-bookworm)
+type Tag struct {
+	Raw        string
+	Prefix     string // Prefix of the version (v), empty when missing
+	Version    *semver.Version
+	Precision  int    // Number of version components written in the tag (e.g. 2 for 1.21)
+	Prerelease string // Pre-release identifier following the version (e.g. rc1), empty for stable tags
+	Variant    string // Suffix following the version (e.g. alpine, slim-bookworm), empty when missing
+}
+
+// ParseTag splits a tag on the first dash into a semantic version and a suffix.
+// The suffix starts with the pre-release identifier, if any, followed by the variant:
+// 1.26.0-rc1-alpine is the rc1 pre-release of the alpine variant.
+// Tags are only comparable with tags of the same variant.
+func ParseTag(tag string) (Tag, error) {
+	versionPart, suffix, _ := strings.Cut(tag, "-")
+
+	prerelease, variant := "", suffix
+	if first, rest, _ := strings.Cut(suffix, "-"); prereleasePattern.MatchString(first) {
+		prerelease, variant = first, rest
+	}
+
+	versionString := versionPart
+	if prerelease != "" {
+		versionString += "-" + prerelease
+	}
+	version, err := semver.NewVersion(versionString)
+	if err != nil {
+		return Tag{}, fmt.Errorf("tag '%s' does not start with a semantic version: %w", tag, err)
+	}
+
+	parsed := Tag{Raw: tag, Version: version, Prerelease: prerelease, Variant: variant}
+
+	if strings.HasPrefix(versionPart, "v") || strings.HasPrefix(versionPart, "V") {
+		parsed.Prefix = versionPart[:1]
+	}
+	components, _, _ := strings.Cut(strings.TrimPrefix(versionPart, parsed.Prefix), "+")
+	parsed.Precision = strings.Count(components, ".") + 1
+
+	return parsed, nil
+}
+
+// SameStyle reports whether other is written like t: same prefix, precision and variant.
+// Suggesting a tag of the same style keeps it pullable and consistent with the running one.
+func (t Tag) SameStyle(other Tag) bool {
+	return t.Prefix == other.Prefix && t.Precision == other.Precision && t.Variant == other.Variant
+}
@@ -0,0 +1,156 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestParseTag(t *testing.T) {
+	testCases := []struct {
+		input             string
+		expectedPrefix    string
+		expectedVersion   string
+		expectedPrecision int
+		expectedVariant   string
+		wantErr           bool
+	}{
+		{input: "13", expectedVersion: "13.0.0", expectedPrecision: 1},
+		{input: "1.25", expectedVersion: "1.25.0", expectedPrecision: 2},
+		{input: "v2.1.0", expectedPrefix: "v", expectedVersion: "2.1.0", expectedPrecision: 3},
+		{input: "1.25-alpine", expectedVersion: "1.25.0", expectedPrecision: 2, expectedVariant: "alpine"},
+		{input: "3.12-slim-bookworm", expectedVersion: "3.12.0", expectedPrecision: 2, expectedVariant: "slim-bookworm"},
+		{input: "1.26.0-rc1", expectedVersion: "1.26.0-rc1", expectedPrecision: 3},
+		{input: "1.26-beta.2-alpine", expectedVersion: "1.26.0-beta.2", expectedPrecision: 2, expectedVariant: "alpine"},
+		{input: "1.2.3-0.1", expectedVersion: "1.2.3-0.1", expectedPrecision: 3},
+		{input: "1.0.0-m1-jdk17", expectedVersion: "1.0.0-m1", expectedPrecision: 3, expectedVariant: "jdk17"},
+		{input: "2.0.0-x.7", expectedVersion: "2.0.0", expectedPrecision: 3, expectedVariant: "x.7"},
+		{input: "latest", wantErr: true},
+		{input: "alpine-3.19", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			tag, err := ParseTag(tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseTag() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if tag.Prefix != tc.expectedPrefix || tag.Version.String() != tc.expectedVersion || tag.Precision != tc.expectedPrecision || tag.Variant != tc.expectedVariant {
+				t.Errorf("ParseTag() = (%q, %s, %d, %q), expected (%q, %s, %d, %q)",
+					tag.Prefix, tag.Version, tag.Precision, tag.Variant,
+					tc.expectedPrefix, tc.expectedVersion, tc.expectedPrecision, tc.expectedVariant)
+			}
+		})
+	}
+}
+
+func TestFindLatestUpdate_Variants(t *testing.T) {
+	available := []string{"1.25-alpine", "1.27", "1.29-alpine", "1.29-alpine-slim", "1.30", "3.11-slim-bookworm", "3.13-slim-bookworm", "3.13-slim-bullseye"}
+
+	testCases := []struct {
+		current  string
+		expected string
+	}{
+		{current: "1.25-alpine", expected: "1.29-alpine"},
+		{current: "1.25", expected: "1.30"},
+		{current: "3.12-slim-bookworm", expected: "3.13-slim-bookworm"},
+		{current: "1.29-alpine-slim", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.current, func(t *testing.T) {
+			latest, _, err := FindLatestUpdate(tc.current, available, DefaultUpdatePolicy())
+			if err != nil {
+				t.Fatalf("FindLatestUpdate() unexpected error = %v", err)
+			}
+			if latest != tc.expected {
+				t.Errorf("FindLatestUpdate() = %q, expected %q", latest, tc.expected)
+			}
+		})
+	}
+}
+
+func TestFindLatestUpdate_Style(t *testing.T) {
+	available := []string{"1.21", "1.21.4", "1.29", "1.29.0", "1.29.1", "2", "3", "v2.1.0", "v2.3.4", "2.4.0"}
+
+	testCases := []struct {
+		current  string
+		expected string
+	}{
+		{current: "1.21", expected: "1.29"},
+		{current: "1.21.4", expected: "2.4.0"},
+		{current: "v2.1.0", expected: "v2.3.4"},
+		{current: "2", expected: "3"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.current, func(t *testing.T) {
+			latest, _, err := FindLatestUpdate(tc.current, available, DefaultUpdatePolicy())
+			if err != nil {
+				t.Fatalf("FindLatestUpdate() unexpected error = %v", err)
+			}
+			if latest != tc.expected {
+				t.Errorf("FindLatestUpdate() = %q, expected %q", latest, tc.expected)
+			}
+		})
+	}
+}
+
+func TestFindLatestUpdate_Prerelease(t *testing.T) {
+	available := []string{"1.25.0", "1.26.0-rc1", "1.26.0-rc2", "1.26.0", "1.27.0-beta.1", "1.27.0-beta.1-alpine", "1.26.0-alpine"}
+
+	testCases := []struct {
+		name       string
+		current    string
+		prerelease PrereleasePolicy
+		expected   string
+	}{
+		{name: "Excluded by default", current: "1.25.0", prerelease: PrereleaseExclude, expected: "1.26.0"},
+		{name: "Excluded for pre-releases", current: "1.26.0-rc1", prerelease: PrereleaseExclude, expected: "1.26.0"},
+		{name: "Auto on stable", current: "1.25.0", prerelease: PrereleaseAuto, expected: "1.26.0"},
+		{name: "Auto on pre-release", current: "1.26.0-rc1", prerelease: PrereleaseAuto, expected: "1.27.0-beta.1"},
+		{name: "Included", current: "1.25.0", prerelease: PrereleaseInclude, expected: "1.27.0-beta.1"},
+		{name: "Included with variant", current: "1.26.0-alpine", prerelease: PrereleaseInclude, expected: "1.27.0-beta.1-alpine"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := DefaultUpdatePolicy()
+			policy.Prerelease = tc.prerelease
+
+			latest, _, err := FindLatestUpdate(tc.current, available, policy)
+			if err != nil {
+				t.Fatalf("FindLatestUpdate() unexpected error = %v", err)
+			}
+			if latest != tc.expected {
+				t.Errorf("FindLatestUpdate() = %q, expected %q", latest, tc.expected)
+			}
+		})
+	}
+}
+
+func TestFindLatestUpdate_IgnoreMetadata(t *testing.T) {
+	available := []string{"1.25.3-debian-12-r5", "1.25.4-debian-12-r0", "1.27.1-debian-12-r2", "1.27.2-debian-11-r0"}
+
+	policy := DefaultUpdatePolicy()
+	latest, _, err := FindLatestUpdate("1.25.3-debian-12-r5", available, policy)
+	if err != nil {
+		t.Fatalf("FindLatestUpdate() unexpected error = %v", err)
+	}
+	if latest != "" {
+		t.Errorf("Expected revisions to be compared as variants without ignore patterns, got %q", latest)
+	}
+
+	policy.IgnoreMetadata, err = ParseMetadataPatterns([]string{`-r[0-9]+$`})
+	if err != nil {
+		t.Fatalf("ParseMetadataPatterns() unexpected error = %v", err)
+	}
+	latest, _, err = FindLatestUpdate("1.25.3-debian-12-r5", available, policy)
+	if err != nil {
+		t.Fatalf("FindLatestUpdate() unexpected error = %v", err)
+	}
+	if latest != "1.27.1-debian-12-r2" {
+		t.Errorf("FindLatestUpdate() = %q, expected 1.27.1-debian-12-r2", latest)
+	}
+}
@@ -5,50 +5,97 @@ import (
 	"log"
 	"slices"
 	"sort"
-
-	"github.com/Masterminds/semver/v3"
 )
 
-// FindLatestUpdate finds the latest semver-compatible update for a given current tag
-// from a list of available tags. It returns the latest found tag and a boolean indicating if an update was found.
-func FindLatestUpdate(currentTag string, availableTags []string) (string, bool, error) {
-	currentVersion, err := semver.NewVersion(currentTag)
+// Updates lists the newest candidate tags for each kind of version change.
+// Empty fields mean that no candidate of that kind is available.
+type Updates struct {
+	Patch  string // Newest version with the same major and minor
+	Minor  string // Newest version with the same major and a higher minor
+	Major  string // Newest version with a higher major
+	Latest string // Newest candidate allowed by the policy level
+}
+
+// FindUpdates finds the newest patch, minor and major updates for a given current tag
+// from a list of available tags, considering only the tags allowed by policy and written like the
+// current one: same prefix (v), number of version components and variant suffix, once stripped of
+// the build metadata ignored by policy. Pre-releases are considered according to the policy.
+// The returned updates are tags as listed by the registry.
+func FindUpdates(currentTag string, availableTags []string, policy UpdatePolicy) (Updates, error) {
+	var updates Updates
+
+	current, err := ParseTag(currentTag)
 	if err != nil {
 		// If the current tag is not a valid semver
 		log.Printf("WARNING: Current tag '%s' is not a valid semantic version. Skipping strict SemVer comparison.", currentTag)
 		// Fallback: If current tag is 'latest' and it's in the list, no update.
 		if currentTag == "latest" {
 			if slices.Contains(availableTags, currentTag) {
-				return "", false, nil // "latest" found and is current, no update.
+				return updates, nil // "latest" found and is current, no update.
 			}
 		}
-		return "", false, fmt.Errorf("current tag '%s' is not a valid semver: %w", currentTag, err)
+		return updates, fmt.Errorf("current tag '%s' is not a valid semver: %w", currentTag, err)
 	}
+	current = policy.stripMetadata(current)
 
-	var versions []*semver.Version
+	var candidates []Tag
 	for _, tag := range availableTags {
-		v, err := semver.NewVersion(tag)
+		candidate, err := ParseTag(tag)
 		if err != nil {
 			// Ignore tags that are not valid semantic versions
 			continue
 		}
-		versions = append(versions, v)
+		candidate = policy.stripMetadata(candidate)
+		// Only tags of the same style (e.g. v1.2 or 1.2.3-alpine) can replace the current one
+		if !current.SameStyle(candidate) {
+			continue
+		}
+		if !candidate.Version.GreaterThan(current.Version) || !policy.allows(current, candidate) {
+			continue
+		}
+		candidates = append(candidates, candidate)
 	}
 
-	if len(versions) == 0 {
-		return "", false, nil // No valid semver tags found to compare against
+	// Sort candidates in descending order, so that the first candidate of each kind is the newest
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Version.GreaterThan(candidates[j].Version)
+	})
+
+	for _, candidate := range candidates {
+		switch {
+		case candidate.Version.Major() != current.Version.Major():
+			if updates.Major == "" {
+				updates.Major = candidate.Raw
+			}
+		case candidate.Version.Minor() != current.Version.Minor():
+			if updates.Minor == "" {
+				updates.Minor = candidate.Raw
+			}
+		default:
+			if updates.Patch == "" {
+				updates.Patch = candidate.Raw
+			}
+		}
 	}
 
-	// Sort versions in descending order
-	sort.Sort(sort.Reverse(semver.Collection(versions)))
+	updates.Latest = updates.Patch
+	if policy.Level != PolicyPatch && updates.Minor != "" {
+		updates.Latest = updates.Minor
+	}
+	if policy.Level != PolicyPatch && policy.Level != PolicyMinor && updates.Major != "" {
+		updates.Latest = updates.Major
+	}
 
-	// The latest version is the first one after sorting
-	latestAvailableVersion := versions[0]
+	return updates, nil
+}
 
-	// Check if the latest available version is newer than the current version
-	if latestAvailableVersion.GreaterThan(currentVersion) {
-		return latestAvailableVersion.String(), true, nil // Update found
+// FindLatestUpdate finds the latest semver-compatible update for a given current tag
+// from a list of available tags, considering only the tags allowed by policy.
+// It returns the latest found tag and a boolean indicating if an update was found.
+func FindLatestUpdate(currentTag string, availableTags []string, policy UpdatePolicy) (string, bool, error) {
+	updates, err := FindUpdates(currentTag, availableTags, policy)
+	if err != nil {
+		return "", false, err
 	}
-
-	return "", false, nil // No update found or current is already the latest
+	return updates.Latest, updates.Latest != "", nil
 }
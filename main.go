@@ -4,32 +4,34 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/FedericoAntoniazzi/chuck/cache"
+	"github.com/FedericoAntoniazzi/chuck/config"
 	"github.com/FedericoAntoniazzi/chuck/core"
 	"github.com/FedericoAntoniazzi/chuck/output"
+	"github.com/FedericoAntoniazzi/chuck/registry/acr"
+	"github.com/FedericoAntoniazzi/chuck/registry/artifactory"
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
 	"github.com/FedericoAntoniazzi/chuck/registry/dockerhub"
+	"github.com/FedericoAntoniazzi/chuck/registry/ecr"
+	"github.com/FedericoAntoniazzi/chuck/registry/ghcr"
+	"github.com/FedericoAntoniazzi/chuck/registry/gitlab"
+	"github.com/FedericoAntoniazzi/chuck/registry/google"
+	"github.com/FedericoAntoniazzi/chuck/registry/harbor"
+	"github.com/FedericoAntoniazzi/chuck/registry/nexus"
+	"github.com/FedericoAntoniazzi/chuck/registry/oci"
+	"github.com/FedericoAntoniazzi/chuck/registry/quay"
 	"github.com/FedericoAntoniazzi/chuck/types"
-	"github.com/Masterminds/semver/v3"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-const (
-	defaultDBFileName    = "chuck.db"
-	defaultLoggingLevel  = "warn"
-	defaultLoggingFormat = "text"
-)
-
-// registryClient defines the capabilities of a generic client for container registries
-type registryClient interface {
-	// GetTags fetches all available tags for a given image from the registry
-	GetTags(ctx context.Context, image types.Image) ([]string, error)
-}
-
 func defineLogger(logLevel string, logFormat string) (*zap.SugaredLogger, error) {
 	var encoderConfig zapcore.EncoderConfig
 	var encoder zapcore.Encoder
@@ -56,8 +58,9 @@ func defineLogger(logLevel string, logFormat string) (*zap.SugaredLogger, error)
 	}
 	atomicLevel := zap.NewAtomicLevelAt(parsedLevel)
 
-	// Lock the output to allow safe concurrent writes
-	outputSyncer := zapcore.Lock(os.Stdout)
+	// Lock the output to allow safe concurrent writes.
+	// Logs go to stderr, keeping stdout for the report.
+	outputSyncer := zapcore.Lock(os.Stderr)
 
 	core := zapcore.NewCore(encoder, outputSyncer, atomicLevel)
 	baseLogger := zap.New(core, zap.AddCaller())
@@ -65,27 +68,232 @@ func defineLogger(logLevel string, logFormat string) (*zap.SugaredLogger, error)
 	return baseLogger.Sugar(), nil
 }
 
+// writeReport writes the statuses to w in the selected output format
+func writeReport(w io.Writer, allUpdateStatuses []types.ImageUpdateStatus, rateLimit *output.RateLimit, outputFormat string, all bool, logger *zap.SugaredLogger) error {
+	switch outputFormat {
+	case "json", "yaml":
+		hostname, err := os.Hostname()
+		if err != nil {
+			logger.Warnf("could not determine hostname: %v", err)
+		}
+
+		report := output.NewReport(allUpdateStatuses, hostname, time.Now())
+		report.RateLimit = rateLimit
+		if outputFormat == "yaml" {
+			return output.WriteYAML(w, report)
+		}
+		return output.WriteJSON(w, report)
+	case "csv":
+		return output.WriteCSV(w, allUpdateStatuses)
+	case "tab":
+		output.WriteTable(w, allUpdateStatuses, all, logger)
+		return nil
+	default:
+		if err := output.WriteText(w, allUpdateStatuses, all); err != nil {
+			return err
+		}
+		if rateLimit != nil {
+			_, err := fmt.Fprintf(w, "Docker Hub rate limit: %d/%d requests remaining\n", rateLimit.Remaining, rateLimit.Limit)
+			return err
+		}
+		return nil
+	}
+}
+
+// registryDigestClient lists the tags of images and resolves the digests of their tags
+type registryDigestClient interface {
+	core.RegistryClient
+	core.DigestClient
+}
+
+// newRegistryClients returns the dedicated clients listing tags and resolving digests, by registry
+// name or pattern. Docker Hub is left out, as its client is configured separately.
+func newRegistryClients(registries []config.RegistryConfig, credentials auth.CredentialStore, logger *zap.SugaredLogger) (map[string]core.RegistryClient, map[string]core.DigestClient) {
+	registryClients := make(map[string]core.RegistryClient)
+	digestClients := make(map[string]core.DigestClient)
+	register := func(registry string, client registryDigestClient) {
+		registryClients[registry] = client
+		digestClients[registry] = client
+	}
+
+	register(ghcr.Registry, ghcr.NewClient(ghcr.WithCredentials(credentials)))
+	register(quay.Registry, quay.NewClient(quay.WithCredentials(credentials), quay.WithLogger(logger)))
+	register(gitlab.Registry, gitlab.NewClient(gitlab.Registry, gitlab.WithCredentials(credentials)))
+
+	// Cloud registries are matched by host name pattern
+	register(ecr.RegistryPattern, ecr.NewClient(ecr.WithCredentials(credentials)))
+	googleClient := google.NewClient(google.WithCredentials(credentials))
+	for _, pattern := range google.RegistryPatterns {
+		register(pattern, googleClient)
+	}
+	register(acr.RegistryPattern, acr.NewClient(acr.WithCredentials(credentials)))
+
+	// Self-hosted registries configured with a type or an endpoint in chuck.yaml take precedence
+	for _, registry := range registries {
+		client := newConfiguredRegistryClient(registry, credentials)
+		if client == nil {
+			continue
+		}
+		logger.Debugf("Using %s client for registry %s", registryType(registry), registry.Host)
+		register(registry.Host, client)
+	}
+
+	return registryClients, digestClients
+}
+
+// registryType returns the type of a configured registry, oci when unset
+func registryType(registry config.RegistryConfig) string {
+	if registry.Type == "" {
+		return "oci"
+	}
+	return strings.ToLower(registry.Type)
+}
+
+// newConfiguredRegistryClient returns the client of a registry configured with a type or an endpoint,
+// or nil when the registry is reached with the default clients
+func newConfiguredRegistryClient(registry config.RegistryConfig, credentials auth.CredentialStore) registryDigestClient {
+	switch registryType(registry) {
+	case "harbor":
+		return harbor.NewClient(registry.Host,
+			harbor.WithEndpoint(registry.Endpoint),
+			harbor.WithCredentials(credentials),
+		)
+	case "nexus":
+		return nexus.NewClient(registry.Host,
+			nexus.WithEndpoint(registry.Endpoint),
+			nexus.WithRepository(registry.Repository),
+			nexus.WithCredentials(credentials),
+		)
+	case "artifactory":
+		return artifactory.NewClient(registry.Host,
+			artifactory.WithEndpoint(registry.Endpoint),
+			artifactory.WithRepository(registry.Repository),
+			artifactory.WithAPIKey(registry.APIKey),
+			artifactory.WithCredentials(credentials),
+		)
+	case "gitlab":
+		return gitlab.NewClient(registry.Host,
+			gitlab.WithEndpoint(registry.Endpoint),
+			gitlab.WithAuthURL(registry.AuthEndpoint),
+			gitlab.WithCredentials(credentials),
+		)
+	}
+
+	if registry.Endpoint != "" {
+		return oci.NewClient(
+			oci.WithBaseURL(registry.Endpoint),
+			oci.WithCredentials(auth.AliasCredentials{Store: credentials, Host: registry.Host}),
+		)
+	}
+	return nil
+}
+
+// stringList is a flag collecting every occurrence of a repeated option
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
 func main() {
 	// --- CLI Flags Definition ---
-	logFormat := flag.String("logFormat", defaultLoggingFormat, "Log format (text, json)")
-	logLevel := flag.String("logLevel", defaultLoggingLevel, "Configure the logging level (debug, info, warn, error)")
-	dbPath := flag.String("db-path", defaultDBFileName, "Path to the SQLite database file")
-	outputFormat := flag.String("output", "text", "Output format (text, tab)")
+	// Flags override the configuration file and the environment variables
+	defaults := config.Default()
+	configPath := flag.String("config", "", "Path to the configuration file (default $XDG_CONFIG_HOME/chuck/chuck.yaml, then /etc/chuck/chuck.yaml)")
+	logFormat := flag.String("logFormat", defaults.Log.Format, "Log format (text, json)")
+	logLevel := flag.String("logLevel", defaults.Log.Level, "Configure the logging level (debug, info, warn, error)")
+	dbPath := flag.String("db-path", defaults.DBPath, "Path to the SQLite database file")
+	outputFormat := flag.String("output", defaults.Output.Format, "Output format (text, tab, json, yaml, csv)")
+	reportAll := flag.Bool("all", defaults.Output.All, "Report every scanned container in text and tab output, not only those with an update")
+	outputFile := flag.String("output-file", defaults.Output.File, "Write the report to this file instead of stdout, replacing it atomically")
+	ociFallback := flag.Bool("oci-fallback", defaults.OCIFallback, "Query registries without a dedicated client through the Distribution v2 API, instead of reporting them as unsupported")
+	dockerHubMaxPages := flag.Int("dockerhub-max-pages", defaults.DockerHubMaxPages, "Maximum number of tag pages fetched from Docker Hub for each image")
+	parallelism := flag.Int("parallel", defaults.Parallelism, "Maximum number of concurrent tag listings")
+	registryParallelism := flag.Int("registry-parallel", defaults.RegistryParallelism, "Maximum number of concurrent tag listings against the same registry")
+	noCache := flag.Bool("no-cache", defaults.Cache.Disabled, "Do not use the persistent tag cache")
+	refreshCache := flag.Bool("refresh", false, "Ignore cached tags and refresh them from the registries")
+	cacheTTL := flag.Duration("cache-ttl", defaults.Cache.TTL, "Time cached tags are used without contacting the registry")
+	cacheDir := flag.String("cache-dir", defaults.Cache.Dir, "Directory of the persistent tag cache (default $XDG_CACHE_HOME/chuck)")
+	dockerConfigPath := flag.String("docker-config", auth.DefaultDockerConfigPath(), "Path to the Docker CLI configuration file holding registry credentials")
+	var includeRules, excludeRules stringList
+	flag.Var(&includeRules, "include", "Only check containers matching this field=pattern rule (fields: name, image, registry, tag, label:<key>; ~ prefixes a regexp). Repeatable")
+	flag.Var(&excludeRules, "exclude", "Skip containers matching this field=pattern rule (same syntax as -include). Repeatable")
+	policyLevel := flag.String("policy", defaults.Versions.Policy, "Highest version change reported as an update, unless overridden by the chuck.policy label: patch, minor or any")
+	prerelease := flag.String("prerelease", defaults.Versions.Prerelease, "Pre-release tags suggested as updates: exclude, auto (only to containers running a pre-release) or include")
+	var ignoreMetadata stringList
+	flag.Var(&ignoreMetadata, "ignore-metadata", "Ignore build metadata matching this regexp in variant suffixes (e.g. -r[0-9]+$). Repeatable")
+	showSkipped := flag.Bool("show-skipped", defaults.Filters.ShowSkipped, "Report containers skipped by filter rules")
 
 	flag.Parse()
 
+	// --- Configuration ---
+	cfg, loadedConfigPath, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("error loading configuration: %v", err)
+	}
+
+	flagOverrides := map[string]func(){
+		"logFormat":           func() { cfg.Log.Format = *logFormat },
+		"logLevel":            func() { cfg.Log.Level = *logLevel },
+		"db-path":             func() { cfg.DBPath = *dbPath },
+		"output":              func() { cfg.Output.Format = *outputFormat },
+		"all":                 func() { cfg.Output.All = *reportAll },
+		"output-file":         func() { cfg.Output.File = *outputFile },
+		"dockerhub-max-pages": func() { cfg.DockerHubMaxPages = *dockerHubMaxPages },
+		"oci-fallback":        func() { cfg.OCIFallback = *ociFallback },
+		"parallel":            func() { cfg.Parallelism = *parallelism },
+		"registry-parallel":   func() { cfg.RegistryParallelism = *registryParallelism },
+		"no-cache":            func() { cfg.Cache.Disabled = *noCache },
+		"cache-ttl":           func() { cfg.Cache.TTL = *cacheTTL },
+		"cache-dir":           func() { cfg.Cache.Dir = *cacheDir },
+		"docker-config":       func() { cfg.DockerConfig = *dockerConfigPath },
+		"include":             func() { cfg.Filters.Include = includeRules },
+		"exclude":             func() { cfg.Filters.Exclude = excludeRules },
+		"show-skipped":        func() { cfg.Filters.ShowSkipped = *showSkipped },
+		"policy":              func() { cfg.Versions.Policy = *policyLevel },
+		"prerelease":          func() { cfg.Versions.Prerelease = *prerelease },
+		"ignore-metadata":     func() { cfg.Versions.IgnoreMetadata = ignoreMetadata },
+	}
+	flag.Visit(func(f *flag.Flag) {
+		if override, ok := flagOverrides[f.Name]; ok {
+			override()
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		if loadedConfigPath != "" {
+			log.Fatalf("invalid configuration (%s):\n%v", loadedConfigPath, err)
+		}
+		log.Fatalf("invalid configuration:\n%v", err)
+	}
+	if cfg.DockerConfig == "" {
+		cfg.DockerConfig = auth.DefaultDockerConfigPath()
+	}
+
 	// --- Logging Setup ---
-	logger, err := defineLogger(*logLevel, *logFormat)
+	logger, err := defineLogger(cfg.Log.Level, cfg.Log.Format)
 	if err != nil {
 		log.Fatalf("error creating logger: %v", err)
 	}
 	defer logger.Sync()
 
+	if loadedConfigPath != "" {
+		logger.Debugf("Using configuration file: %s", loadedConfigPath)
+	}
+	if len(cfg.Notifications) > 0 {
+		logger.Warn("notification targets are configured, but notifications are not supported yet")
+	}
+
 	// --- Database Path Handling ---
 	// Resolve the absolute path for the database file
-	resolvedDBPath, err := filepath.Abs(*dbPath)
+	resolvedDBPath, err := filepath.Abs(cfg.DBPath)
 	if err != nil {
-		logger.Fatal("could not resolve absolute path for database file", "path", *dbPath, "err", err)
+		logger.Fatal("could not resolve absolute path for database file", "path", cfg.DBPath, "err", err)
 	}
 	logger.Debugf("Using database file: %s", resolvedDBPath)
 
@@ -96,144 +304,137 @@ func main() {
 	// Create a background context for Docker API calls
 	ctx := context.Background()
 
-	registryClients := make(map[string]registryClient)
-	registryClients["docker.io"] = dockerhub.NewClient()
-	// Hint: registryClients["ghcr.io"] = github.NewClient()
-
-	containers, err := core.GetRunningContainerImages(ctx, logger)
+	// Reuse the credentials saved by `docker login`
+	dockerConfig, err := auth.LoadDockerConfig(cfg.DockerConfig, logger)
 	if err != nil {
-		logger.Fatalf("Failed to get running containers: %v", err)
+		logger.Fatalf("Failed to load docker config: %v", err)
 	}
 
-	if len(containers) == 0 {
-		logger.Info("No running containers found")
-		return
-	}
-
-	logger.Infof("Found %d running containers", len(containers))
-	// Store the images which tags have already been queried
-	uniqueImages := make(map[string][]string)
-
-	var allUpdateStatuses []types.ImageUpdateStatus
-
-	for _, cnt := range containers {
-		containerName := ""
-
-		if len(cnt.Names[0]) > 0 {
-			containerName = strings.TrimPrefix(cnt.Names[0], "/")
+	// Credentials set in chuck.yaml take precedence over the Docker ones
+	configCredentials := make(auth.HostCredentials)
+	for _, registry := range cfg.Registries {
+		creds := auth.Credentials{
+			Username:      registry.Username,
+			Password:      registry.Password,
+			RegistryToken: registry.Token,
 		}
-
-		logger.Debug("processing container ", containerName)
-
-		status := types.ImageUpdateStatus{
-			ContainerID:   cnt.ID,
-			ContainerName: containerName,
-			OriginalTag:   "latest",
-			StatusMessage: "Processing",
+		if registry.KeyFile != "" {
+			key, err := os.ReadFile(registry.KeyFile)
+			if err != nil {
+				logger.Fatalf("Failed to read key file of registry %s: %v", registry.Host, err)
+			}
+			creds = auth.Credentials{Username: google.JSONKeyUsername, Password: string(key)}
 		}
+		configCredentials[registry.Host] = creds
+	}
+	credentials := auth.ChainedCredentials{configCredentials, dockerConfig}
+
+	dockerHubClient := dockerhub.NewClient(
+		dockerhub.WithCredentials(credentials),
+		dockerhub.WithLogger(logger),
+		dockerhub.WithMaxPages(cfg.DockerHubMaxPages),
+	)
+	registryClients, digestClients := newRegistryClients(cfg.Registries, credentials, logger)
+	registryClients["docker.io"] = dockerHubClient
+
+	// Registries without a dedicated client are queried through the Distribution v2 API,
+	// unless the fallback is disabled and they are reported as unsupported
+	ociClient := oci.NewClient(oci.WithCredentials(credentials))
+	var defaultRegistryClient core.RegistryClient
+	if cfg.OCIFallback {
+		defaultRegistryClient = ociClient
+	}
 
-		image, err := core.ParseImageName(cnt.Image)
-		if err != nil {
-			status.StatusMessage = fmt.Sprintln("Error parsing image name")
-			status.Error = err.Error()
-			allUpdateStatuses = append(allUpdateStatuses, status)
-			logger.Warn("skipping invalid image name", "image", cnt.Image, "error", err)
-			continue
+	// Serve repeated lookups from the persistent tag cache
+	if !cfg.Cache.Disabled {
+		if cfg.Cache.Dir == "" {
+			cfg.Cache.Dir, err = cache.DefaultDir()
+			if err != nil {
+				logger.Fatalf("Failed to locate cache directory: %v", err)
+			}
 		}
+		logger.Debugf("Using tag cache directory: %s", cfg.Cache.Dir)
 
-		status.Image = image
-		status.OriginalTag = image.Tag
-
-		// Check if the registry is supported
-		if _, ok := registryClients[image.Registry]; !ok {
-			status.StatusMessage = fmt.Sprintln("Unsupported registry")
-			status.Error = "Unsupported registry"
-			allUpdateStatuses = append(allUpdateStatuses, status)
-			logger.Warn("skipping unsupported registry (", image.Registry, ") for image ", image.Raw)
-			continue
+		tagStore := cache.NewStore(cfg.Cache.Dir)
+		for registry, client := range registryClients {
+			registryClients[registry] = cache.NewClient(client, tagStore, cfg.Cache.TTL, *refreshCache, logger)
 		}
-
-		// Check if the tag is valid SemVer
-		_, err = semver.NewVersion(image.Tag)
-		if err != nil {
-			status.StatusMessage = fmt.Sprintln("Error parsing image tag")
-			status.Error = err.Error()
-			allUpdateStatuses = append(allUpdateStatuses, status)
-			logger.Warnw("skipping invalid semver tag", "image", image.Raw, "tag", image.Tag)
-			continue
+		if defaultRegistryClient != nil {
+			defaultRegistryClient = cache.NewClient(defaultRegistryClient, tagStore, cfg.Cache.TTL, *refreshCache, logger)
 		}
+	}
 
-		// Check if image tags have already been fetched
-		imageKey := fmt.Sprintf("%s/%s/%s", image.Registry, image.Namespace, image.Name)
-		availableTags, fetched := uniqueImages[imageKey]
-		if !fetched {
-			logger.Debugf("fetching tags for image %s", imageKey)
-			// Fetch image tags from registry
-			regClient := registryClients[image.Registry]
-			tags, err := regClient.GetTags(ctx, image)
-			if err != nil {
-				status.StatusMessage = fmt.Sprintln("Error fetching images")
-				status.Error = err.Error()
-				allUpdateStatuses = append(allUpdateStatuses, status)
-				logger.Errorf("error retrieving tags from registry", "image", imageKey, "registry", image.Registry, "error", err)
-				continue
-			}
+	// Containers are listed from the local Docker daemon
+	logger.Info("Connecting to Docker daemon")
+	dockerClient, err := core.NewDockerClient()
+	if err != nil {
+		logger.Fatalf("Failed to connect to Docker daemon: %v", err)
+	}
+	defer dockerClient.Close()
+	dockerSource := core.NewDockerSource(dockerClient, logger)
 
-			uniqueImages[imageKey] = tags
-			availableTags = tags
-			logger.Debugf("Found %d tags for image %s", len(tags), imageKey)
-		}
+	filter, err := core.NewFilter(cfg.Filters.Include, cfg.Filters.Exclude)
+	if err != nil {
+		logger.Fatalf("Invalid filter: %v", err)
+	}
 
-		latestUpdateTag, isUpdateAvailable, err := core.FindLatestUpdate(image.Tag, availableTags)
-		if err != nil {
-			status.StatusMessage = fmt.Sprintln("Error comparing tags")
-			status.Error = err.Error()
-			allUpdateStatuses = append(allUpdateStatuses, status)
-			logger.Error("unexpected error during semver checks", "error", err)
-		}
+	policy := core.DefaultUpdatePolicy()
+	policy.Level, err = core.ParsePolicyLevel(cfg.Versions.Policy)
+	if err != nil {
+		logger.Fatalf("Invalid update policy: %v", err)
+	}
+	policy.Prerelease, err = core.ParsePrereleasePolicy(cfg.Versions.Prerelease)
+	if err != nil {
+		logger.Fatalf("Invalid update policy: %v", err)
+	}
+	policy.IgnoreMetadata, err = core.ParseMetadataPatterns(cfg.Versions.IgnoreMetadata)
+	if err != nil {
+		logger.Fatalf("Invalid update policy: %v", err)
+	}
 
-		status.UpdateAvailable = isUpdateAvailable
-		status.LatestAvailableTag = latestUpdateTag
+	checkerOptions := []core.CheckerOption{
+		core.WithDefaultRegistryClient(defaultRegistryClient),
+		// Digests are never cached, as they change under the same tag
+		core.WithDigestClient(ociClient),
+		core.WithFilter(filter, cfg.Filters.ShowSkipped),
+		core.WithUpdatePolicy(policy),
+		core.WithParallelism(cfg.Parallelism, cfg.RegistryParallelism),
+		core.WithLogger(logger),
+	}
+	for registry, client := range registryClients {
+		checkerOptions = append(checkerOptions, core.WithRegistryClient(registry, client))
+	}
+	for registry, client := range digestClients {
+		checkerOptions = append(checkerOptions, core.WithRegistryDigestClient(registry, client))
+	}
 
-		if isUpdateAvailable {
-			status.StatusMessage = "Update available"
-			allUpdateStatuses = append(allUpdateStatuses, status)
-			logger.Debugf("Container %s (%s) can be upgraded to %s", containerName, imageKey, latestUpdateTag)
-		} else {
-			status.StatusMessage = "No update available"
-			logger.Debugf("checked updates for %s (%s). No updates available", containerName, imageKey)
-		}
+	checker := core.NewChecker(dockerSource, checkerOptions...)
+
+	allUpdateStatuses, err := checker.Check(ctx)
+	if err != nil {
+		logger.Fatalf("Failed to check containers: %v", err)
 	}
 
-	// Init tabbed printer when is required
-	var tabbedPrinter *output.TabbedPrinter
-	if *outputFormat == "tab" {
-		tabbedPrinter = output.NewTabbedPrinter(logger)
-		tabbedPrinter.SetHeaders("CONTAINER_NAME", "IMAGE", "CURRENT TAG", "LATEST TAG")
-	}
-
-	for _, update := range allUpdateStatuses {
-		if update.UpdateAvailable {
-			switch *outputFormat {
-			case "text":
-				fmt.Printf("Container %s (%s) can be upgraded to %s\n",
-					update.ContainerName,
-					update.Image.Raw,
-					update.LatestAvailableTag,
-				)
-			case "tab":
-				tabbedPrinter.AddRow(
-					update.ContainerName,
-					update.Image.Name,
-					update.OriginalTag,
-					update.LatestAvailableTag,
-				)
-			}
-		}
+	if len(allUpdateStatuses) == 0 {
+		logger.Info("No running containers found")
 	}
 
-	if *outputFormat == "tab" {
-		tabbedPrinter.Print()
+	// Report the remaining Docker Hub quota, useful to schedule the next run
+	var rateLimit *output.RateLimit
+	if quota := dockerHubClient.RateLimit(); quota.Known() {
+		logger.Infof("Docker Hub rate limit: %d/%d requests remaining", quota.Remaining, quota.Limit)
+		rateLimit = &output.RateLimit{Limit: quota.Limit, Remaining: quota.Remaining, Reset: quota.Reset.UTC()}
 	}
 
+	write := func(w io.Writer) error {
+		return writeReport(w, allUpdateStatuses, rateLimit, cfg.Output.Format, cfg.Output.All, logger)
+	}
+	if cfg.Output.File != "" {
+		err = output.WriteFileAtomic(cfg.Output.File, write)
+	} else {
+		err = write(os.Stdout)
+	}
+	if err != nil {
+		logger.Fatalf("Failed to write report: %v", err)
+	}
 }
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/FedericoAntoniazzi/chuck/config"
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// TestNewRegistryClients tests that every dedicated client also resolves digests,
+// so that digest checks use the same authentication as tag listings
+func TestNewRegistryClients(t *testing.T) {
+	registries := []config.RegistryConfig{{Host: "harbor.example.com", Type: "harbor"}}
+	registryClients, digestClients := newRegistryClients(registries, auth.HostCredentials{}, zap.NewNop().Sugar())
+
+	for _, registry := range []string{"ghcr.io", "quay.io", "registry.gitlab.com", "harbor.example.com", "*.azurecr.io"} {
+		assert.Contains(t, digestClients, registry)
+	}
+	for registry, client := range registryClients {
+		assert.Same(t, client, digestClients[registry], registry)
+	}
+}
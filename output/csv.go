@@ -0,0 +1,73 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/FedericoAntoniazzi/chuck/types"
+)
+
+// csvColumns lists the CSV columns of ImageUpdateStatus, following its csv struct tags.
+// Nested structs are flattened by joining the tags (e.g. image_registry).
+var csvColumns = flattenCSVColumns(reflect.TypeOf(types.ImageUpdateStatus{}), nil, "")
+
+// csvColumn is a column of the CSV report along with the path of the field it reads
+type csvColumn struct {
+	header string
+	index  []int
+}
+
+// WriteCSV writes a CSV document with a header row and one row for each status
+func WriteCSV(w io.Writer, statuses []types.ImageUpdateStatus) error {
+	writer := csv.NewWriter(w)
+
+	headers := make([]string, len(csvColumns))
+	for i, column := range csvColumns {
+		headers[i] = column.header
+	}
+	if err := writer.Write(headers); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, status := range statuses {
+		value := reflect.ValueOf(status)
+		row := make([]string, len(csvColumns))
+		for i, column := range csvColumns {
+			row[i] = fmt.Sprint(value.FieldByIndex(column.index).Interface())
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV report: %w", err)
+	}
+	return nil
+}
+
+// flattenCSVColumns walks the fields of t carrying a csv tag
+func flattenCSVColumns(t reflect.Type, index []int, prefix string) []csvColumn {
+	var columns []csvColumn
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("csv")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		fieldIndex := append(append([]int{}, index...), i)
+		if field.Type.Kind() == reflect.Struct {
+			columns = append(columns, flattenCSVColumns(field.Type, fieldIndex, prefix+tag+"_")...)
+			continue
+		}
+
+		columns = append(columns, csvColumn{header: prefix + tag, index: fieldIndex})
+	}
+
+	return columns
+}
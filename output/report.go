@@ -0,0 +1,98 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ReportVersion is the version of the report document, increased on breaking changes
+const ReportVersion = 1
+
+// Summary counts the containers of a report by outcome
+type Summary struct {
+	Total               int `json:"total" yaml:"total"`
+	UpdatesAvailable    int `json:"updatesAvailable" yaml:"updatesAvailable"`
+	UpToDate            int `json:"upToDate" yaml:"upToDate"`
+	UnsupportedRegistry int `json:"unsupportedRegistry" yaml:"unsupportedRegistry"`
+	ParseErrors         int `json:"parseErrors" yaml:"parseErrors"`
+	FetchErrors         int `json:"fetchErrors" yaml:"fetchErrors"`
+	Skipped             int `json:"skipped" yaml:"skipped"` // Filtered and disabled containers
+}
+
+// RateLimit is the Docker Hub request quota left at the end of a run
+type RateLimit struct {
+	Limit     int       `json:"limit" yaml:"limit"`
+	Remaining int       `json:"remaining" yaml:"remaining"`
+	Reset     time.Time `json:"reset,omitzero" yaml:"reset,omitempty"`
+}
+
+// Report is the complete result of a run, meant to be consumed by other tools
+type Report struct {
+	Version     int                       `json:"version" yaml:"version"`
+	GeneratedAt time.Time                 `json:"generatedAt" yaml:"generatedAt"`
+	Host        string                    `json:"host" yaml:"host"`
+	Summary     Summary                   `json:"summary" yaml:"summary"`
+	RateLimit   *RateLimit                `json:"rateLimit,omitempty" yaml:"rateLimit,omitempty"` // Unset when Docker Hub was not queried
+	Containers  []types.ImageUpdateStatus `json:"containers" yaml:"containers"`
+}
+
+// NewReport builds the report of the statuses collected on host
+func NewReport(statuses []types.ImageUpdateStatus, host string, generatedAt time.Time) Report {
+	report := Report{
+		Version:     ReportVersion,
+		GeneratedAt: generatedAt.UTC(),
+		Host:        host,
+		Containers:  statuses,
+	}
+	if report.Containers == nil {
+		report.Containers = []types.ImageUpdateStatus{}
+	}
+
+	report.Summary.Total = len(statuses)
+	for _, status := range statuses {
+		switch status.Status {
+		case types.StatusUpdateAvailable, types.StatusNewDigest:
+			report.Summary.UpdatesAvailable++
+		case types.StatusUpToDate:
+			report.Summary.UpToDate++
+		case types.StatusUnsupportedRegistry:
+			report.Summary.UnsupportedRegistry++
+		case types.StatusInvalidImage, types.StatusNonSemverTag, types.StatusInvalidLabel:
+			report.Summary.ParseErrors++
+		case types.StatusFetchError, types.StatusCompareError:
+			report.Summary.FetchErrors++
+		case types.StatusSkipped, types.StatusDisabled:
+			report.Summary.Skipped++
+		}
+	}
+
+	return report
+}
+
+// WriteJSON writes the report as an indented JSON document
+func WriteJSON(w io.Writer, report Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode JSON report: %w", err)
+	}
+	return nil
+}
+
+// WriteYAML writes the report as a YAML document
+func WriteYAML(w io.Writer, report Report) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode YAML report: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode YAML report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testStatuses = []types.ImageUpdateStatus{
+	{
+		ContainerID:        "abc",
+		ContainerName:      "web",
+		Image:              types.Image{Raw: "nginx:1.25", Registry: "docker.io", Namespace: "library", Name: "nginx", Tag: "1.25"},
+		OriginalTag:        "1.25",
+		LatestAvailableTag: "1.27.0",
+		LatestMinorTag:     "1.27.0",
+		UpdateAvailable:    true,
+		Status:             types.StatusUpdateAvailable,
+		StatusMessage:      "Update available",
+	},
+	{
+		ContainerID:   "def",
+		ContainerName: "cache",
+		Image:         types.Image{Raw: "redis:7.2.0", Registry: "docker.io", Namespace: "library", Name: "redis", Tag: "7.2.0"},
+		OriginalTag:   "7.2.0",
+		Status:        types.StatusUpToDate,
+		StatusMessage: "No update available",
+	},
+	{
+		ContainerID:   "ghi",
+		ContainerName: "proxy",
+		Image:         types.Image{Raw: "traefik:latest", Registry: "docker.io", Namespace: "library", Name: "traefik", Tag: "latest"},
+		OriginalTag:   "latest",
+		Status:        types.StatusNonSemverTag,
+		StatusMessage: "Error parsing image tag",
+		Error:         "Invalid Semantic Version",
+	},
+	{
+		ContainerID:   "jkl",
+		ContainerName: "private",
+		Image:         types.Image{Raw: "registry.example.com/app:1.0", Registry: "registry.example.com", Namespace: ".", Name: "app", Tag: "1.0"},
+		OriginalTag:   "1.0",
+		Status:        types.StatusFetchError,
+		StatusMessage: "Error fetching images",
+		Error:         "received non-OK status code from registry (401)",
+	},
+}
+
+func TestNewReport(t *testing.T) {
+	generatedAt := time.Date(2025, 7, 1, 14, 0, 0, 0, time.FixedZone("CEST", 2*3600))
+	report := NewReport(testStatuses, "docker-01", generatedAt)
+
+	assert.Equal(t, ReportVersion, report.Version)
+	assert.Equal(t, "docker-01", report.Host)
+	assert.Equal(t, time.UTC, report.GeneratedAt.Location())
+	assert.Equal(t, Summary{Total: 4, UpdatesAvailable: 1, UpToDate: 1, ParseErrors: 1, FetchErrors: 1}, report.Summary)
+	assert.Equal(t, testStatuses, report.Containers)
+}
+
+func TestWriteJSON(t *testing.T) {
+	generatedAt := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, NewReport(testStatuses[:1], "docker-01", generatedAt)))
+
+	expected := `{
+  "version": 1,
+  "generatedAt": "2025-07-01T12:00:00Z",
+  "host": "docker-01",
+  "summary": {
+    "total": 1,
+    "updatesAvailable": 1,
+    "upToDate": 0,
+    "unsupportedRegistry": 0,
+    "parseErrors": 0,
+    "fetchErrors": 0,
+    "skipped": 0
+  },
+  "containers": [
+    {
+      "containerId": "abc",
+      "containerName": "web",
+      "image": {
+        "raw": "nginx:1.25",
+        "registry": "docker.io",
+        "namespace": "library",
+        "name": "nginx",
+        "tag": "1.25"
+      },
+      "originalTag": "1.25",
+      "latestAvailableTag": "1.27.0",
+      "latestMinorTag": "1.27.0",
+      "updateAvailable": true,
+      "status": "update_available",
+      "statusMessage": "Update available"
+    }
+  ]
+}
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestWriteJSON_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, NewReport(nil, "docker-01", time.Now())))
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, []any{}, decoded["containers"])
+}
+
+func TestWriteYAML(t *testing.T) {
+	generatedAt := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteYAML(&buf, NewReport(testStatuses[:1], "docker-01", generatedAt)))
+
+	expected := `version: 1
+generatedAt: 2025-07-01T12:00:00Z
+host: docker-01
+summary:
+  total: 1
+  updatesAvailable: 1
+  upToDate: 0
+  unsupportedRegistry: 0
+  parseErrors: 0
+  fetchErrors: 0
+  skipped: 0
+containers:
+  - containerId: abc
+    containerName: web
+    image:
+      raw: nginx:1.25
+      registry: docker.io
+      namespace: library
+      name: nginx
+      tag: "1.25"
+    originalTag: "1.25"
+    latestAvailable_tag: 1.27.0
+    latestPatchTag: ""
+    latestMinorTag: 1.27.0
+    latestMajorTag: ""
+    currentTagModified: ""
+    latestTagModified: ""
+    currentDigest: ""
+    latestDigest: ""
+    updateAvailable: true
+    status: update_available
+    statusMessage: Update available
+    error: ""
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestReport_RateLimit(t *testing.T) {
+	report := NewReport(nil, "docker-01", time.Now())
+	report.RateLimit = &RateLimit{Limit: 100, Remaining: 42, Reset: time.Date(2025, 7, 1, 18, 0, 0, 0, time.UTC)}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, report))
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, map[string]any{"limit": 100.0, "remaining": 42.0, "reset": "2025-07-01T18:00:00Z"}, decoded["rateLimit"])
+
+	buf.Reset()
+	require.NoError(t, WriteYAML(&buf, report))
+	assert.Contains(t, buf.String(), "rateLimit:\n  limit: 100\n  remaining: 42\n  reset: 2025-07-01T18:00:00Z\n")
+
+	// Runs which did not query Docker Hub have no quota to report
+	buf.Reset()
+	require.NoError(t, WriteJSON(&buf, NewReport(nil, "docker-01", time.Now())))
+	assert.NotContains(t, buf.String(), "rateLimit")
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, testStatuses[:3]))
+
+	expected := `container_id,container_name,image_raw,image_registry,image_namespace,image_name,image_tag,original_tag,latest_available_tag,latest_patch_tag,latest_minor_tag,latest_major_tag,current_tag_modified,latest_tag_modified,current_digest,latest_digest,update_available,status,status_message,error
+abc,web,nginx:1.25,docker.io,library,nginx,1.25,1.25,1.27.0,,1.27.0,,,,,,true,update_available,Update available,
+def,cache,redis:7.2.0,docker.io,library,redis,7.2.0,7.2.0,,,,,,,,,false,up_to_date,No update available,
+ghi,proxy,traefik:latest,docker.io,library,traefik,latest,latest,,,,,,,,,false,non_semver_tag,Error parsing image tag,Invalid Semantic Version
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, os.WriteFile(path, []byte("previous"), 0o644))
+
+	// A failed write leaves the previous report untouched
+	err := WriteFileAtomic(path, func(w io.Writer) error {
+		_, _ = w.Write([]byte("partial"))
+		return errors.New("encoding failed")
+	})
+	assert.EqualError(t, err, "encoding failed")
+	content, _ := os.ReadFile(path)
+	assert.Equal(t, "previous", string(content))
+
+	require.NoError(t, WriteFileAtomic(path, func(w io.Writer) error {
+		return WriteJSON(w, NewReport(nil, "docker-01", time.Now()))
+	}))
+	content, _ = os.ReadFile(path)
+	assert.Contains(t, string(content), `"host": "docker-01"`)
+
+	// No temporary file is left behind
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	assert.Len(t, entries, 1)
+}
@@ -2,6 +2,7 @@ package output
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"text/tabwriter"
@@ -17,8 +18,13 @@ type TabbedPrinter struct {
 
 // NewTabbedPrinter creates a default TabbedPrinter
 func NewTabbedPrinter(log *zap.SugaredLogger) *TabbedPrinter {
+	return NewTabbedPrinterWithWriter(os.Stdout, log)
+}
+
+// NewTabbedPrinterWithWriter creates a TabbedPrinter printing to w
+func NewTabbedPrinterWithWriter(w io.Writer, log *zap.SugaredLogger) *TabbedPrinter {
 	return &TabbedPrinter{
-		writer: tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', tabwriter.AlignRight),
+		writer: tabwriter.NewWriter(w, 0, 8, 1, '\t', tabwriter.AlignRight),
 		logger: log,
 	}
 }
@@ -0,0 +1,125 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"go.uber.org/zap"
+)
+
+// statusLabels are the human-readable descriptions of each check status
+var statusLabels = map[types.CheckStatus]string{
+	types.StatusUpdateAvailable:     "update available",
+	types.StatusNewDigest:           "newer image under same tag",
+	types.StatusUpToDate:            "up to date",
+	types.StatusUnsupportedRegistry: "unsupported registry",
+	types.StatusInvalidImage:        "invalid image",
+	types.StatusNonSemverTag:        "non-semver tag",
+	types.StatusInvalidLabel:        "invalid label",
+	types.StatusFetchError:          "fetch error",
+	types.StatusCompareError:        "compare error",
+	types.StatusSkipped:             "skipped by filter",
+	types.StatusDisabled:            "disabled by label",
+}
+
+// StatusLabel returns the human-readable description of a check status
+func StatusLabel(status types.CheckStatus) string {
+	if label, ok := statusLabels[status]; ok {
+		return label
+	}
+	return string(status)
+}
+
+// WriteText writes one sentence per container with an available update.
+// When all is set, every container is reported along with the reason it could not be checked.
+// Containers skipped by filter or disabled by label are always reported, as they are only listed on request.
+func WriteText(w io.Writer, statuses []types.ImageUpdateStatus, all bool) error {
+	for _, status := range statuses {
+		var err error
+
+		switch {
+		case status.Status == types.StatusNewDigest:
+			_, err = fmt.Fprintf(w, "Container %s (%s) has a newer image published under the same tag\n", status.ContainerName, status.Image.Raw)
+		case status.UpdateAvailable:
+			_, err = fmt.Fprintf(w, "Container %s (%s) can be upgraded to %s\n",
+				status.ContainerName,
+				imageWithAge(status),
+				status.LatestAvailableTag,
+			)
+		case status.Status.Skipped():
+			_, err = fmt.Fprintf(w, "Container %s (%s) %s\n", status.ContainerName, status.Image.Raw, strings.ToLower(status.StatusMessage))
+		case !all:
+			continue
+		case status.Status == types.StatusUpToDate:
+			_, err = fmt.Fprintf(w, "Container %s (%s) is up to date\n", status.ContainerName, imageWithAge(status))
+		default:
+			_, err = fmt.Fprintf(w, "Container %s (%s) could not be checked: %s (%s)\n",
+				status.ContainerName,
+				status.Image.Raw,
+				StatusLabel(status.Status),
+				status.Error,
+			)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// imageWithAge returns the image reference of a status, followed by the push date
+// of its tag when the registry reports it
+func imageWithAge(status types.ImageUpdateStatus) string {
+	modified, err := time.Parse(time.RFC3339, status.CurrentTagModified)
+	if err != nil {
+		return status.Image.Raw
+	}
+	return fmt.Sprintf("%s, pushed on %s", status.Image.Raw, modified.Format(time.DateOnly))
+}
+
+// WriteTable writes the containers with an available update as aligned columns,
+// along with the newest PATCH, MINOR and MAJOR candidates.
+// When all is set, every container is reported with its STATUS and ERROR,
+// including the ones skipped by filter or disabled by label.
+func WriteTable(w io.Writer, statuses []types.ImageUpdateStatus, all bool, logger *zap.SugaredLogger) {
+	tabbedPrinter := NewTabbedPrinterWithWriter(w, logger)
+	if all {
+		tabbedPrinter.SetHeaders("CONTAINER_NAME", "IMAGE", "CURRENT TAG", "LATEST TAG", "PATCH", "MINOR", "MAJOR", "STATUS", "ERROR")
+	} else {
+		tabbedPrinter.SetHeaders("CONTAINER_NAME", "IMAGE", "CURRENT TAG", "LATEST TAG", "PATCH", "MINOR", "MAJOR")
+	}
+
+	for _, status := range statuses {
+		switch {
+		case all:
+			tabbedPrinter.AddRow(
+				status.ContainerName,
+				status.Image.Name,
+				status.OriginalTag,
+				status.LatestAvailableTag,
+				status.LatestPatchTag,
+				status.LatestMinorTag,
+				status.LatestMajorTag,
+				StatusLabel(status.Status),
+				status.Error,
+			)
+		case status.UpdateAvailable:
+			tabbedPrinter.AddRow(
+				status.ContainerName,
+				status.Image.Name,
+				status.OriginalTag,
+				status.LatestAvailableTag,
+				status.LatestPatchTag,
+				status.LatestMinorTag,
+				status.LatestMajorTag,
+			)
+		}
+	}
+
+	tabbedPrinter.Print()
+}
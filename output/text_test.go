@@ -0,0 +1,82 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestStatusLabel(t *testing.T) {
+	assert.Equal(t, "up to date", StatusLabel(types.StatusUpToDate))
+	assert.Equal(t, "non-semver tag", StatusLabel(types.StatusNonSemverTag))
+	assert.Equal(t, "custom", StatusLabel(types.CheckStatus("custom")))
+}
+
+func TestWriteText(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteText(&buf, testStatuses, false))
+	assert.Equal(t, "Container web (nginx:1.25) can be upgraded to 1.27.0\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, WriteText(&buf, testStatuses, true))
+	assert.Equal(t, `Container web (nginx:1.25) can be upgraded to 1.27.0
+Container cache (redis:7.2.0) is up to date
+Container proxy (traefik:latest) could not be checked: non-semver tag (Invalid Semantic Version)
+Container private (registry.example.com/app:1.0) could not be checked: fetch error (received non-OK status code from registry (401))
+`, buf.String())
+}
+
+func TestWriteTable(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+
+	var buf bytes.Buffer
+	WriteTable(&buf, testStatuses, false, logger)
+	assert.Equal(t, "CONTAINER_NAME\tIMAGE\tCURRENT TAG\tLATEST TAG\tPATCH\tMINOR\tMAJOR\nweb\t\tnginx\t1.25\t\t1.27.0\t\t\t1.27.0\t\n", buf.String())
+
+	buf.Reset()
+	WriteTable(&buf, testStatuses, true, logger)
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 5)
+	assert.Contains(t, string(lines[0]), "STATUS")
+	assert.Contains(t, string(lines[2]), "up to date")
+	assert.Contains(t, string(lines[4]), "fetch error")
+}
+
+func TestWriteText_Skipped(t *testing.T) {
+	statuses := []types.ImageUpdateStatus{
+		{ContainerName: "sidecar", Image: types.Image{Raw: "envoy:1.30", Name: "envoy"}, OriginalTag: "1.30", Status: types.StatusSkipped, StatusMessage: "Skipped by filter: name=sidecar"},
+		{ContainerName: "legacy", Image: types.Image{Raw: "postgres:12", Name: "postgres"}, OriginalTag: "12", Status: types.StatusDisabled, StatusMessage: "Disabled by label: chuck.enable=false"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteText(&buf, statuses, false))
+	assert.Equal(t, `Container sidecar (envoy:1.30) skipped by filter: name=sidecar
+Container legacy (postgres:12) disabled by label: chuck.enable=false
+`, buf.String())
+
+	// The default table keeps its columns, skipped containers are only listed with their STATUS in -all mode
+	buf.Reset()
+	WriteTable(&buf, statuses, false, zap.NewNop().Sugar())
+	assert.Equal(t, "CONTAINER_NAME\tIMAGE\tCURRENT TAG\tLATEST TAG\tPATCH\tMINOR\tMAJOR\n", buf.String())
+
+	buf.Reset()
+	WriteTable(&buf, statuses, true, zap.NewNop().Sugar())
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[1], "skipped by filter")
+	assert.Contains(t, lines[2], "disabled by label")
+}
+
+func TestWriteText_TagModified(t *testing.T) {
+	status := testStatuses[0]
+	status.CurrentTagModified = "2024-03-05T10:00:00Z"
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteText(&buf, []types.ImageUpdateStatus{status}, false))
+	assert.Equal(t, "Container web (nginx:1.25, pushed on 2024-03-05) can be upgraded to 1.27.0\n", buf.String())
+}
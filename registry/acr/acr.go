@@ -0,0 +1,246 @@
+package acr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
+	"github.com/FedericoAntoniazzi/chuck/registry/oci"
+	"github.com/FedericoAntoniazzi/chuck/types"
+)
+
+// RegistryPattern matches the names of Azure Container Registry hosts
+const RegistryPattern = "*.azurecr.io"
+
+// refreshTokenLifetime is how long refresh tokens obtained from Azure AD access tokens are reused,
+// well below their actual validity
+const refreshTokenLifetime = time.Hour
+
+// exchangeResponse represents the response of the ACR /oauth2/exchange endpoint
+type exchangeResponse struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// cachedRefreshToken is an ACR refresh token along with the time it is renewed
+type cachedRefreshToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+// Client is the Azure Container Registry client, serving every <name>.azurecr.io registry.
+// Registry access tokens are requested to the /oauth2/token endpoint of the registry with:
+//   - the ACR refresh token saved by az acr login (identity token of the Docker configuration);
+//   - an Azure AD access token configured as token, first exchanged for an ACR refresh token;
+//   - the username and password of a service principal, of the admin user or of a repository token;
+//   - nothing, for registries allowing anonymous pulls.
+type Client struct {
+	credentials auth.CredentialStore
+	endpoint    string // Overrides the registry URL, for tests
+	httpClient  *http.Client
+	now         func() time.Time
+
+	mu            sync.Mutex
+	clients       map[string]*oci.Client        // Distribution clients, per registry
+	refreshTokens map[string]cachedRefreshToken // Refresh tokens exchanged for Azure AD tokens, per registry
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithCredentials sets the store resolving the credentials of each registry
+func WithCredentials(credentials auth.CredentialStore) Option {
+	return func(c *Client) {
+		c.credentials = credentials
+	}
+}
+
+// WithEndpoint sends registry requests to endpoint instead of https://<registry>
+func WithEndpoint(endpoint string) Option {
+	return func(c *Client) {
+		c.endpoint = strings.TrimSuffix(endpoint, "/")
+	}
+}
+
+// NewClient creates and returns a new Azure Container Registry client
+func NewClient(opts ...Option) *Client {
+	client := &Client{
+		httpClient:    &http.Client{Timeout: 15 * time.Second},
+		now:           time.Now,
+		clients:       make(map[string]*oci.Client),
+		refreshTokens: make(map[string]cachedRefreshToken),
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client
+}
+
+// GetTags fetches all available tags for a given image from its Azure Container Registry
+func (c *Client) GetTags(ctx context.Context, image types.Image) ([]string, error) {
+	tags, _, _, err := c.GetTagsIfChanged(ctx, image, "")
+	return tags, err
+}
+
+// GetTagsIfChanged fetches all available tags for a given image unless they still match etag
+func (c *Client) GetTagsIfChanged(ctx context.Context, image types.Image, etag string) ([]string, string, bool, error) {
+	client, err := c.registryClient(image.Registry)
+	if err != nil {
+		return nil, "", false, err
+	}
+	return client.GetTagsIfChanged(ctx, image, etag)
+}
+
+// GetDigest returns the digest of the manifest currently published under the tag of image
+func (c *Client) GetDigest(ctx context.Context, image types.Image) (string, error) {
+	client, err := c.registryClient(image.Registry)
+	if err != nil {
+		return "", err
+	}
+	return client.GetDigest(ctx, image)
+}
+
+// registryClient returns the Distribution client of registry
+func (c *Client) registryClient(registry string) (*oci.Client, error) {
+	if matched, _ := path.Match(RegistryPattern, registry); !matched {
+		return nil, fmt.Errorf("unsupported url for Azure Container Registry: %s", registry)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if client, ok := c.clients[registry]; ok {
+		return client, nil
+	}
+
+	source := auth.TokenSourceFunc(func(ctx context.Context, scope string) (auth.Token, error) {
+		return c.token(ctx, registry, scope)
+	})
+	client := oci.NewClient(
+		oci.WithBaseURL(c.registryURL(registry)),
+		oci.WithTransport(auth.NewBearerTransport(http.DefaultTransport, source)),
+	)
+	c.clients[registry] = client
+	return client, nil
+}
+
+// registryURL returns the base URL of registry
+func (c *Client) registryURL(registry string) string {
+	if c.endpoint != "" {
+		return c.endpoint
+	}
+	return "https://" + registry
+}
+
+// token requests an access token for scope to the /oauth2/token endpoint of registry
+func (c *Client) token(ctx context.Context, registry, scope string) (auth.Token, error) {
+	var creds auth.Credentials
+	if c.credentials != nil {
+		var err error
+		creds, err = c.credentials.Credentials(registry)
+		if err != nil {
+			return auth.Token{}, fmt.Errorf("failed to resolve credentials for %s: %w", registry, err)
+		}
+	}
+
+	refreshToken := creds.IdentityToken
+	if refreshToken == "" && creds.RegistryToken != "" {
+		var err error
+		refreshToken, err = c.exchange(ctx, registry, creds.RegistryToken)
+		if err != nil {
+			return auth.Token{}, err
+		}
+	}
+
+	tokenURL := c.registryURL(registry) + "/oauth2/token"
+
+	var req *http.Request
+	var err error
+	if refreshToken != "" {
+		form := url.Values{}
+		form.Set("grant_type", "refresh_token")
+		form.Set("service", registry)
+		form.Set("scope", scope)
+		form.Set("refresh_token", refreshToken)
+
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+		if err != nil {
+			return auth.Token{}, fmt.Errorf("failed to create HTTP request to ACR token endpoint: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		query := url.Values{}
+		query.Set("service", registry)
+		query.Set("scope", scope)
+
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, tokenURL+"?"+query.Encode(), nil)
+		if err != nil {
+			return auth.Token{}, fmt.Errorf("failed to create HTTP request to ACR token endpoint: %w", err)
+		}
+		if creds.Username != "" || creds.Password != "" {
+			req.SetBasicAuth(creds.Username, creds.Password)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return auth.Token{}, fmt.Errorf("failed to make HTTP request to ACR token endpoint: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	return auth.ReadToken(resp, "ACR token endpoint")
+}
+
+// exchange returns an ACR refresh token of registry obtained for an Azure AD access token
+func (c *Client) exchange(ctx context.Context, registry, aadToken string) (string, error) {
+	c.mu.Lock()
+	cached, ok := c.refreshTokens[registry]
+	c.mu.Unlock()
+	if ok && c.now().Before(cached.expiresAt) {
+		return cached.value, nil
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "access_token")
+	form.Set("service", registry)
+	form.Set("access_token", aadToken)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.registryURL(registry)+"/oauth2/exchange", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create HTTP request to ACR exchange endpoint: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to make HTTP request to ACR exchange endpoint: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("received non-OK status code from ACR exchange endpoint (%d): %s (Body: %s)", resp.StatusCode, resp.Status, string(respBody))
+	}
+
+	var exchangeResp exchangeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&exchangeResp); err != nil {
+		return "", fmt.Errorf("failed to decode ACR exchange endpoint response: %w", err)
+	}
+	if exchangeResp.RefreshToken == "" {
+		return "", fmt.Errorf("ACR exchange endpoint returned an empty refresh token for %s", registry)
+	}
+
+	c.mu.Lock()
+	c.refreshTokens[registry] = cachedRefreshToken{value: exchangeResp.RefreshToken, expiresAt: c.now().Add(refreshTokenLifetime)}
+	c.mu.Unlock()
+
+	return exchangeResp.RefreshToken, nil
+}
@@ -0,0 +1,112 @@
+package acr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const registry = "myregistry.azurecr.io"
+
+// fakeACR is a fake Azure Container Registry serving the tags of team/app. Access tokens are
+// issued for the refresh token "acr-refresh", exchanged for the Azure AD token "aad-token",
+// and for the service principal "sp-id" with password "sp-secret".
+type fakeACR struct {
+	server        *httptest.Server
+	exchangeHits  atomic.Int32
+	anonymousPull bool
+}
+
+func newFakeACR(t *testing.T) *fakeACR {
+	t.Helper()
+
+	f := &fakeACR{}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth2/exchange":
+			f.exchangeHits.Add(1)
+			assert.Equal(t, "access_token", r.FormValue("grant_type"))
+			assert.Equal(t, registry, r.FormValue("service"))
+			if r.FormValue("access_token") != "aad-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"refresh_token": "acr-refresh"})
+		case "/oauth2/token":
+			assert.Equal(t, registry, r.FormValue("service"))
+			assert.Equal(t, "repository:team/app:pull", r.FormValue("scope"))
+
+			user, pass, basic := r.BasicAuth()
+			switch {
+			case r.Method == http.MethodPost && r.FormValue("grant_type") == "refresh_token" && r.FormValue("refresh_token") == "acr-refresh":
+			case r.Method == http.MethodGet && basic && user == "sp-id" && pass == "sp-secret":
+			case r.Method == http.MethodGet && !basic && f.anonymousPull:
+			default:
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "acr-access"})
+		case "/v2/team/app/tags/list":
+			if r.Header.Get("Authorization") != "Bearer acr-access" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": "team/app", "tags": []string{"1.0.0", "1.2.0"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(f.server.Close)
+
+	return f
+}
+
+// TestGetTags_Credentials tests each supported kind of credentials
+func TestGetTags_Credentials(t *testing.T) {
+	f := newFakeACR(t)
+	image := types.Image{Registry: registry, Namespace: "team", Name: "app"}
+
+	for name, creds := range map[string]auth.Credentials{
+		"refresh token":     {Username: "00000000-0000-0000-0000-000000000000", IdentityToken: "acr-refresh"},
+		"azure ad token":    {RegistryToken: "aad-token"},
+		"service principal": {Username: "sp-id", Password: "sp-secret"},
+	} {
+		client := NewClient(WithEndpoint(f.server.URL), WithCredentials(auth.HostCredentials{registry: creds}))
+		tags, err := client.GetTags(context.Background(), image)
+		require.NoError(t, err, name)
+		assert.Equal(t, []string{"1.0.0", "1.2.0"}, tags, name)
+	}
+	assert.Equal(t, int32(1), f.exchangeHits.Load())
+
+	client := NewClient(WithEndpoint(f.server.URL), WithCredentials(auth.HostCredentials{registry: {RegistryToken: "expired"}}))
+	_, err := client.GetTags(context.Background(), image)
+	assert.ErrorContains(t, err, "received non-OK status code from ACR exchange endpoint (401)")
+}
+
+// TestGetTags_Anonymous tests registries allowing anonymous pulls
+func TestGetTags_Anonymous(t *testing.T) {
+	f := newFakeACR(t)
+	image := types.Image{Registry: registry, Namespace: "team", Name: "app"}
+
+	_, err := NewClient(WithEndpoint(f.server.URL)).GetTags(context.Background(), image)
+	assert.ErrorContains(t, err, "received non-OK status code from ACR token endpoint (401)")
+
+	f.anonymousPull = true
+	tags, err := NewClient(WithEndpoint(f.server.URL)).GetTags(context.Background(), image)
+	require.NoError(t, err)
+	assert.Len(t, tags, 2)
+}
+
+// TestGetTags_UnsupportedRegistry tests registries which are not Azure Container Registries
+func TestGetTags_UnsupportedRegistry(t *testing.T) {
+	_, err := NewClient().GetTags(context.Background(), types.Image{Registry: "azurecr.io.example.com", Namespace: "team", Name: "app"})
+	assert.ErrorContains(t, err, "unsupported url for Azure Container Registry")
+}
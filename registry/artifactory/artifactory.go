@@ -0,0 +1,127 @@
+package artifactory
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
+	"github.com/FedericoAntoniazzi/chuck/registry/oci"
+	"github.com/FedericoAntoniazzi/chuck/types"
+)
+
+// apiKeyHeader is the header carrying Artifactory API keys
+const apiKeyHeader = "X-JFrog-Art-Api"
+
+// Client is the client of the Docker repositories of a JFrog Artifactory instance.
+// When a repository key is set, tags are listed through the Docker REST API of that repository
+// (/artifactory/api/docker/<key>/v2/) and the key is removed from images referenced with the
+// repository path method (<host>/<key>/<name>). Otherwise the Docker API at the root of the
+// endpoint is used, as with the subdomain method.
+// Access tokens are configured as the token of the host, API keys with WithAPIKey.
+type Client struct {
+	host        string
+	endpoint    string
+	repository  string
+	apiKey      string
+	credentials auth.CredentialStore
+	oci         *oci.Client
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithCredentials sets the store resolving the credentials of the Artifactory host
+func WithCredentials(credentials auth.CredentialStore) Option {
+	return func(c *Client) {
+		c.credentials = credentials
+	}
+}
+
+// WithEndpoint sets the URL of the Artifactory instance (e.g. https://example.jfrog.io),
+// when it cannot be reached as https://<host>
+func WithEndpoint(endpoint string) Option {
+	return func(c *Client) {
+		if endpoint != "" {
+			c.endpoint = strings.TrimSuffix(strings.TrimSuffix(endpoint, "/"), "/artifactory")
+		}
+	}
+}
+
+// WithRepository queries the Docker repository with the given key
+func WithRepository(repository string) Option {
+	return func(c *Client) {
+		c.repository = strings.Trim(repository, "/")
+	}
+}
+
+// WithAPIKey authenticates every request with an Artifactory API key
+// instead of the credentials of the host
+func WithAPIKey(apiKey string) Option {
+	return func(c *Client) {
+		c.apiKey = apiKey
+	}
+}
+
+// NewClient creates and returns a new client of the Artifactory registry named host
+func NewClient(host string, opts ...Option) *Client {
+	client := &Client{
+		host:     host,
+		endpoint: "https://" + host,
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	baseURL := client.endpoint
+	if client.repository != "" {
+		baseURL += "/artifactory/api/docker/" + client.repository
+	}
+
+	ociOpts := []oci.Option{
+		oci.WithBaseURL(baseURL),
+		oci.WithCredentials(auth.AliasCredentials{Store: client.credentials, Host: host}),
+	}
+	if client.apiKey != "" {
+		ociOpts = append(ociOpts, oci.WithTransport(&apiKeyTransport{base: http.DefaultTransport, apiKey: client.apiKey}))
+	}
+	client.oci = oci.NewClient(ociOpts...)
+
+	return client
+}
+
+// GetTags fetches all available tags for a given image from Artifactory
+func (c *Client) GetTags(ctx context.Context, image types.Image) ([]string, error) {
+	tags, _, _, err := c.GetTagsIfChanged(ctx, image, "")
+	return tags, err
+}
+
+// GetTagsIfChanged fetches all available tags for a given image unless they still match etag
+func (c *Client) GetTagsIfChanged(ctx context.Context, image types.Image, etag string) ([]string, string, bool, error) {
+	if image.Registry != c.host {
+		return nil, "", false, fmt.Errorf("unsupported url for Artifactory registry %s: %s", c.host, image.Registry)
+	}
+	return c.oci.GetTagsIfChanged(ctx, oci.TrimRepositoryPrefix(image, c.repository), etag)
+}
+
+// GetDigest returns the digest of the manifest currently published under the tag of image
+func (c *Client) GetDigest(ctx context.Context, image types.Image) (string, error) {
+	if image.Registry != c.host {
+		return "", fmt.Errorf("unsupported url for Artifactory registry %s: %s", c.host, image.Registry)
+	}
+	return c.oci.GetDigest(ctx, oci.TrimRepositoryPrefix(image, c.repository))
+}
+
+// apiKeyTransport is an http.RoundTripper adding an Artifactory API key to every request
+type apiKeyTransport struct {
+	base   http.RoundTripper
+	apiKey string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *apiKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	authReq := req.Clone(req.Context())
+	authReq.Header.Set(apiKeyHeader, t.apiKey)
+	return t.base.RoundTrip(authReq)
+}
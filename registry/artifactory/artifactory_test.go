@@ -0,0 +1,83 @@
+package artifactory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeArtifactory starts a fake Artifactory serving the tags of team/app in the docker-local
+// repository. Requests are accepted with the API key, or with a token issued to the access token
+// by the repository token endpoint.
+func newFakeArtifactory(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/artifactory/api/docker/docker-local/v2/token":
+			assert.Equal(t, "repository:team/app:pull", r.URL.Query().Get("scope"))
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": "docker-token"})
+		case "/artifactory/api/docker/docker-local/v2/team/app/tags/list", "/v2/team/app/tags/list":
+			authorized := r.Header.Get(apiKeyHeader) == "api-key" ||
+				r.Header.Get("Authorization") == "Bearer access-token" ||
+				r.Header.Get("Authorization") == "Bearer docker-token"
+			if !authorized {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/artifactory/api/docker/docker-local/v2/token",service="artifactory"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": "team/app", "tags": []string{"3.0.0", "3.1.0"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// TestGetTags_APIKey tests API key authentication with the repository path method
+func TestGetTags_APIKey(t *testing.T) {
+	server := newFakeArtifactory(t)
+
+	client := NewClient("art.example.com", WithEndpoint(server.URL+"/artifactory/"), WithRepository("docker-local"), WithAPIKey("api-key"))
+	tags, err := client.GetTags(context.Background(), types.Image{Registry: "art.example.com", Namespace: "docker-local/team", Name: "app"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3.0.0", "3.1.0"}, tags)
+}
+
+// TestGetTags_AccessToken tests access tokens of the host, sent as bearer tokens
+func TestGetTags_AccessToken(t *testing.T) {
+	server := newFakeArtifactory(t)
+
+	credentials := auth.HostCredentials{"docker-local.art.example.com": {RegistryToken: "access-token"}}
+	client := NewClient("docker-local.art.example.com", WithEndpoint(server.URL), WithCredentials(credentials))
+	tags, err := client.GetTags(context.Background(), types.Image{Registry: "docker-local.art.example.com", Namespace: "team", Name: "app"})
+	require.NoError(t, err)
+	assert.Len(t, tags, 2)
+}
+
+// TestGetTags_Anonymous tests anonymous access through the repository token endpoint
+func TestGetTags_Anonymous(t *testing.T) {
+	server := newFakeArtifactory(t)
+
+	client := NewClient("art.example.com", WithEndpoint(server.URL), WithRepository("docker-local"))
+	tags, err := client.GetTags(context.Background(), types.Image{Registry: "art.example.com", Namespace: "team", Name: "app"})
+	require.NoError(t, err)
+	assert.Len(t, tags, 2)
+}
+
+// TestGetTags_UnsupportedRegistry tests the case where the image is hosted on another registry
+func TestGetTags_UnsupportedRegistry(t *testing.T) {
+	tags, err := NewClient("art.example.com").GetTags(context.Background(), types.Image{Registry: "docker.io", Namespace: "library", Name: "nginx"})
+	assert.Nil(t, tags)
+	assert.ErrorContains(t, err, "unsupported url for Artifactory registry art.example.com")
+}
@@ -0,0 +1,267 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChallenges(t *testing.T) {
+	testCases := []struct {
+		name     string
+		header   string
+		expected []Challenge
+	}{
+		{
+			name:     "Empty header",
+			header:   "",
+			expected: nil,
+		},
+		{
+			name:   "Docker Hub bearer challenge",
+			header: `Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull"`,
+			expected: []Challenge{{Scheme: "bearer", Parameters: map[string]string{
+				"realm":   "https://auth.docker.io/token",
+				"service": "registry.docker.io",
+				"scope":   "repository:library/nginx:pull",
+			}}},
+		},
+		{
+			name:   "Unquoted values and spaces",
+			header: `Basic realm=registry, charset="UTF-8"`,
+			expected: []Challenge{{Scheme: "basic", Parameters: map[string]string{
+				"realm":   "registry",
+				"charset": "UTF-8",
+			}}},
+		},
+		{
+			name:   "Multiple challenges",
+			header: `Bearer realm="https://example.com/token",service="example", Basic realm="example"`,
+			expected: []Challenge{
+				{Scheme: "bearer", Parameters: map[string]string{"realm": "https://example.com/token", "service": "example"}},
+				{Scheme: "basic", Parameters: map[string]string{"realm": "example"}},
+			},
+		},
+		{
+			name:   "Escaped quotes",
+			header: `Bearer realm="https://example.com/token",error="say \"hi\""`,
+			expected: []Challenge{{Scheme: "bearer", Parameters: map[string]string{
+				"realm": "https://example.com/token",
+				"error": `say "hi"`,
+			}}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ParseChallenges(tc.header))
+		})
+	}
+}
+
+func TestRepositoryScope(t *testing.T) {
+	assert.Equal(t, "repository:library/nginx:pull", repositoryScope("/v2/library/nginx/tags/list"))
+	assert.Equal(t, "repository:group/sub/app:pull", repositoryScope("/v2/group/sub/app/manifests/1.0"))
+	assert.Equal(t, "repository:app:pull", repositoryScope("/artifactory/api/docker/docker-local/v2/app/tags/list"))
+	assert.Equal(t, "", repositoryScope("/v2/"))
+	assert.Equal(t, "", repositoryScope("/api/v1/repository"))
+}
+
+// fakeTokenRegistry is a registry requiring bearer tokens issued by its own token endpoint
+type fakeTokenRegistry struct {
+	server       *httptest.Server
+	tokenHits    atomic.Int32
+	expiresIn    int
+	username     string
+	password     string
+	refreshToken string
+}
+
+func newFakeTokenRegistry(t *testing.T) *fakeTokenRegistry {
+	t.Helper()
+
+	reg := &fakeTokenRegistry{expiresIn: 300}
+	reg.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			reg.tokenHits.Add(1)
+
+			scope := r.URL.Query().Get("scope")
+			switch {
+			case reg.refreshToken != "":
+				_ = r.ParseForm()
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "refresh_token", r.PostForm.Get("grant_type"))
+				if r.PostForm.Get("refresh_token") != reg.refreshToken {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				scope = r.PostForm.Get("scope")
+			case reg.username != "":
+				user, pass, ok := r.BasicAuth()
+				if !ok || user != reg.username || pass != reg.password {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+			}
+
+			_ = json.NewEncoder(w).Encode(tokenResponse{Token: "token-for-" + scope, ExpiresIn: reg.expiresIn})
+			return
+		}
+
+		expectedScope := "repository:library/app:pull"
+		if r.Header.Get("Authorization") != "Bearer token-for-"+expectedScope {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake",scope="%s"`, reg.server.URL, expectedScope))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"tags":["1.0"]}`))
+	}))
+	t.Cleanup(reg.server.Close)
+
+	return reg
+}
+
+func TestTransport_AnonymousToken(t *testing.T) {
+	reg := newFakeTokenRegistry(t)
+	client := &http.Client{Transport: NewTransport(nil, nil)}
+
+	for range 3 {
+		resp, err := client.Get(reg.server.URL + "/v2/library/app/tags/list")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+	}
+
+	// The token is cached and reused for the following requests
+	assert.Equal(t, int32(1), reg.tokenHits.Load())
+}
+
+func TestTransport_TokenExpiry(t *testing.T) {
+	reg := newFakeTokenRegistry(t)
+	reg.expiresIn = 60
+
+	transport := NewTransport(nil, nil)
+	now := time.Now()
+	transport.now = func() time.Time { return now }
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(reg.server.URL + "/v2/library/app/tags/list")
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+
+	now = now.Add(2 * time.Minute)
+	resp, err = client.Get(reg.server.URL + "/v2/library/app/tags/list")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, int32(2), reg.tokenHits.Load())
+}
+
+func TestTransport_CredentialedToken(t *testing.T) {
+	reg := newFakeTokenRegistry(t)
+	reg.username = "user"
+	reg.password = "secret"
+
+	client := &http.Client{Transport: NewTransport(nil, StaticCredentials{Username: "user", Password: "secret"})}
+	resp, err := client.Get(reg.server.URL + "/v2/library/app/tags/list")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	_ = resp.Body.Close()
+
+	client = &http.Client{Transport: NewTransport(nil, StaticCredentials{Username: "user", Password: "wrong"})}
+	_, err = client.Get(reg.server.URL + "/v2/library/app/tags/list")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "received non-OK status code from token server (401)")
+}
+
+func TestTransport_RefreshToken(t *testing.T) {
+	reg := newFakeTokenRegistry(t)
+	reg.refreshToken = "refresh"
+
+	client := &http.Client{Transport: NewTransport(nil, StaticCredentials{IdentityToken: "refresh"})}
+	resp, err := client.Get(reg.server.URL + "/v2/library/app/tags/list")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	_ = resp.Body.Close()
+}
+
+func TestTransport_Basic(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "user" || pass != "secret" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Without credentials the challenge is returned to the caller
+	resp, err := (&http.Client{Transport: NewTransport(nil, nil)}).Get(server.URL + "/v2/app/tags/list")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	_ = resp.Body.Close()
+
+	resp, err = (&http.Client{Transport: NewTransport(nil, StaticCredentials{Username: "user", Password: "secret"})}).Get(server.URL + "/v2/app/tags/list")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	_ = resp.Body.Close()
+}
+
+func TestTransport_NoChallenge(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		assert.Empty(t, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	resp, err := (&http.Client{Transport: NewTransport(nil, nil)}).Get(server.URL + "/v2/app/tags/list")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	_ = resp.Body.Close()
+	assert.Equal(t, int32(1), hits.Load())
+}
+
+func TestChainedCredentials(t *testing.T) {
+	store := ChainedCredentials{
+		HostCredentials{"ghcr.io": {Username: "config", Password: "secret"}, "docker.io": {RegistryToken: "hub"}},
+		StaticCredentials{Username: "fallback", Password: "fallback"},
+	}
+
+	creds, err := store.Credentials("ghcr.io")
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{Username: "config", Password: "secret"}, creds)
+
+	creds, err = store.Credentials("registry-1.docker.io")
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{RegistryToken: "hub"}, creds)
+
+	creds, err = store.Credentials("quay.io")
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{Username: "fallback", Password: "fallback"}, creds)
+}
+
+func TestAliasCredentials(t *testing.T) {
+	store := AliasCredentials{
+		Store: HostCredentials{"nexus.example.com": {Username: "ci", Password: "secret"}},
+		Host:  "nexus.example.com",
+	}
+
+	creds, err := store.Credentials("nexus.example.com:8083")
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{Username: "ci", Password: "secret"}, creds)
+
+	creds, err = AliasCredentials{Host: "nexus.example.com"}.Credentials("nexus.example.com")
+	assert.NoError(t, err)
+	assert.True(t, creds.IsEmpty())
+}
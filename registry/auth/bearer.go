@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Token is a bearer token obtained from a registry-specific exchange
+type Token struct {
+	Value     string
+	ExpiresIn time.Duration // Lifetime of the token, defaultTokenLifetime when zero
+}
+
+// TokenSource obtains the token authorizing requests on a repository scope
+// (e.g. repository:owner/app:pull), for registries not answering with standard challenges
+// or requiring a dedicated exchange (e.g. access keys for an ECR token)
+type TokenSource interface {
+	Token(ctx context.Context, scope string) (Token, error)
+}
+
+// TokenSourceFunc adapts a function to the TokenSource interface
+type TokenSourceFunc func(ctx context.Context, scope string) (Token, error)
+
+// Token calls f(ctx, scope)
+func (f TokenSourceFunc) Token(ctx context.Context, scope string) (Token, error) {
+	return f(ctx, scope)
+}
+
+// BearerTransport is an http.RoundTripper authenticating every request upfront with the token
+// returned by a TokenSource for its repository scope. Tokens are cached per scope until they
+// expire; a cached token rejected by the registry is replaced by a fresh one and the request retried once.
+type BearerTransport struct {
+	base   http.RoundTripper
+	source TokenSource
+	now    func() time.Time
+
+	mu     sync.Mutex
+	tokens map[string]cachedToken
+}
+
+// NewBearerTransport creates a BearerTransport wrapping base
+func NewBearerTransport(base http.RoundTripper, source TokenSource) *BearerTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &BearerTransport{
+		base:   base,
+		source: source,
+		now:    time.Now,
+		tokens: make(map[string]cachedToken),
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *BearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Requests already carrying credentials are left untouched
+	if req.Header.Get("Authorization") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	scope := repositoryScope(req.URL.Path)
+	token, cached, err := t.token(req.Context(), scope)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(withAuthorization(req, "Bearer "+token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	t.mu.Lock()
+	delete(t.tokens, scope)
+	t.mu.Unlock()
+
+	// A cached token may have been revoked before its expiry: retry once with a fresh one.
+	// Requests with a body can only be replayed if it can be read again.
+	if !cached || (req.Body != nil && req.GetBody == nil) {
+		return resp, nil
+	}
+
+	token, _, err = t.token(req.Context(), scope)
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	retry := withAuthorization(req, "Bearer "+token)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to replay request body: %w", err)
+		}
+		retry.Body = body
+	}
+
+	resp, err = t.base.RoundTrip(retry)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		t.mu.Lock()
+		delete(t.tokens, scope)
+		t.mu.Unlock()
+	}
+	return resp, err
+}
+
+// token returns a cached token for scope or obtains a new one from the source.
+// cached reports whether the token was reused from a previous request.
+func (t *BearerTransport) token(ctx context.Context, scope string) (value string, cached bool, err error) {
+	t.mu.Lock()
+	entry, ok := t.tokens[scope]
+	t.mu.Unlock()
+	if ok && t.now().Before(entry.expiresAt) {
+		return entry.value, true, nil
+	}
+
+	token, err := t.source.Token(ctx, scope)
+	if err != nil {
+		return "", false, err
+	}
+
+	lifetime := token.ExpiresIn
+	if lifetime <= 0 {
+		lifetime = defaultTokenLifetime
+	}
+
+	t.mu.Lock()
+	t.tokens[scope] = cachedToken{value: token.Value, expiresAt: t.now().Add(lifetime - tokenExpiryMargin)}
+	t.mu.Unlock()
+
+	return token.Value, false, nil
+}
+
+// ReadToken decodes the response of a token endpoint (token or access_token, with optional expires_in).
+// server names the endpoint in error messages.
+func ReadToken(resp *http.Response, server string) (Token, error) {
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return Token{}, fmt.Errorf("received non-OK status code from %s (%d): %s (Body: %s)", server, resp.StatusCode, resp.Status, string(respBody))
+	}
+
+	var tokenResp tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return Token{}, fmt.Errorf("failed to decode %s response: %w", server, err)
+	}
+
+	token := Token{Value: tokenResp.Token, ExpiresIn: time.Duration(tokenResp.ExpiresIn) * time.Second}
+	if token.Value == "" {
+		token.Value = tokenResp.AccessToken
+	}
+	if token.Value == "" {
+		return Token{}, fmt.Errorf("%s returned an empty token", server)
+	}
+	return token, nil
+}
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBearerTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-for-repository:owner/app:pull" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	var scopes []string
+	transport := NewBearerTransport(nil, TokenSourceFunc(func(_ context.Context, scope string) (Token, error) {
+		scopes = append(scopes, scope)
+		return Token{Value: "token-for-" + scope, ExpiresIn: time.Minute}, nil
+	}))
+	now := time.Now()
+	transport.now = func() time.Time { return now }
+	client := &http.Client{Transport: transport}
+
+	for range 2 {
+		resp, err := client.Get(server.URL + "/v2/owner/app/tags/list")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+	}
+	assert.Equal(t, []string{"repository:owner/app:pull"}, scopes)
+
+	// Expired tokens are renewed
+	now = now.Add(2 * time.Minute)
+	resp, err := client.Get(server.URL + "/v2/owner/app/tags/list")
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Len(t, scopes, 2)
+}
+
+func TestBearerTransport_Revoked(t *testing.T) {
+	valid := "first"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+valid {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	tokens := []string{"first", "second", "third"}
+	issued := 0
+	transport := NewBearerTransport(nil, TokenSourceFunc(func(_ context.Context, _ string) (Token, error) {
+		issued++
+		return Token{Value: tokens[issued-1], ExpiresIn: time.Hour}, nil
+	}))
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL + "/v2/owner/app/tags/list")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	_ = resp.Body.Close()
+
+	// A cached token revoked before its expiry is replaced and the request retried
+	valid = "second"
+	resp, err = client.Get(server.URL + "/v2/owner/app/tags/list")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	_ = resp.Body.Close()
+	assert.Equal(t, 2, issued)
+
+	// Requests are only retried once
+	valid = "none"
+	resp, err = client.Get(server.URL + "/v2/owner/app/tags/list")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	_ = resp.Body.Close()
+	assert.Equal(t, 3, issued)
+	assert.Empty(t, transport.tokens)
+}
+
+func TestReadToken(t *testing.T) {
+	testCases := []struct {
+		name     string
+		status   int
+		body     string
+		expected Token
+		wantErr  string
+	}{
+		{name: "Token", status: http.StatusOK, body: `{"token":"abc","expires_in":300}`, expected: Token{Value: "abc", ExpiresIn: 5 * time.Minute}},
+		{name: "Access token", status: http.StatusOK, body: `{"access_token":"def"}`, expected: Token{Value: "def"}},
+		{name: "Empty token", status: http.StatusOK, body: `{}`, wantErr: "empty token"},
+		{name: "Denied", status: http.StatusForbidden, body: `denied`, wantErr: "403"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tc.status, Status: http.StatusText(tc.status), Body: io.NopCloser(strings.NewReader(tc.body))}
+			token, err := ReadToken(resp, "token server")
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, token)
+		})
+	}
+}
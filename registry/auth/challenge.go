@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"strings"
+)
+
+// Challenge represents a single authentication challenge sent by a registry
+// in the WWW-Authenticate header (e.g. Bearer realm="https://auth.docker.io/token",service="registry.docker.io")
+type Challenge struct {
+	Scheme     string            // Authentication scheme, lowercased (e.g. bearer, basic)
+	Parameters map[string]string // Challenge parameters (e.g. realm, service, scope)
+}
+
+// ParseChallenges parses the value of a WWW-Authenticate header into its challenges
+func ParseChallenges(header string) []Challenge {
+	var challenges []Challenge
+	rest := strings.TrimSpace(header)
+
+	for rest != "" {
+		scheme, remaining, _ := strings.Cut(rest, " ")
+		challenge := Challenge{
+			Scheme:     strings.ToLower(strings.TrimSpace(scheme)),
+			Parameters: make(map[string]string),
+		}
+		rest = strings.TrimSpace(remaining)
+
+		// Read key=value pairs until a token without '=' starts a new challenge
+		for rest != "" {
+			key, afterKey, found := strings.Cut(rest, "=")
+			if !found || strings.ContainsAny(strings.TrimSpace(key), " ,") {
+				break
+			}
+
+			value, remaining := readParameterValue(strings.TrimSpace(afterKey))
+			challenge.Parameters[strings.ToLower(strings.TrimSpace(key))] = value
+			rest = strings.TrimLeft(strings.TrimSpace(remaining), ",")
+			rest = strings.TrimSpace(rest)
+		}
+
+		if challenge.Scheme != "" {
+			challenges = append(challenges, challenge)
+		}
+	}
+
+	return challenges
+}
+
+// readParameterValue reads a quoted or token parameter value and returns the remaining input
+func readParameterValue(input string) (string, string) {
+	if !strings.HasPrefix(input, `"`) {
+		value, remaining, _ := strings.Cut(input, ",")
+		return strings.TrimSpace(value), remaining
+	}
+
+	var value strings.Builder
+	for i := 1; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			if i+1 < len(input) {
+				i++
+				value.WriteByte(input[i])
+			}
+		case '"':
+			return value.String(), input[i+1:]
+		default:
+			value.WriteByte(input[i])
+		}
+	}
+
+	return value.String(), ""
+}
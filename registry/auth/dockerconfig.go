@@ -0,0 +1,272 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// dockerHubServerURL is the key used by the Docker CLI to store Docker Hub credentials
+	dockerHubServerURL = "https://index.docker.io/v1/"
+	// credentialHelperPrefix is the prefix of credential helper executables (e.g. docker-credential-pass)
+	credentialHelperPrefix = "docker-credential-"
+	// identityTokenUsername is the username returned by helpers storing identity tokens
+	identityTokenUsername = "<token>"
+	// helperTimeout bounds the run of a credential helper, which may wait on a locked keychain
+	helperTimeout = 5 * time.Second
+)
+
+// dockerHubHosts are the hosts sharing the Docker Hub credentials
+var dockerHubHosts = []string{"docker.io", "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com", "hub.docker.com"}
+
+// dockerAuthConfig represents an entry of the auths section of the Docker CLI configuration
+type dockerAuthConfig struct {
+	Auth          string `json:"auth,omitempty"`
+	Username      string `json:"username,omitempty"`
+	Password      string `json:"password,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+	RegistryToken string `json:"registrytoken,omitempty"`
+}
+
+// helperCredentials represents the output of `docker-credential-<helper> get`
+type helperCredentials struct {
+	ServerURL string `json:"ServerURL,omitempty"`
+	Username  string `json:"Username,omitempty"`
+	Secret    string `json:"Secret,omitempty"`
+}
+
+// DockerConfig is a CredentialStore reading the credentials saved by `docker login`
+// from the Docker CLI configuration file and its credential helpers
+type DockerConfig struct {
+	Auths       map[string]dockerAuthConfig `json:"auths,omitempty"`
+	CredsStore  string                      `json:"credsStore,omitempty"`
+	CredHelpers map[string]string           `json:"credHelpers,omitempty"`
+
+	logger *zap.SugaredLogger
+	mu     sync.Mutex
+	cache  map[string]*resolvedCredentials // Resolved credentials, per host
+}
+
+// resolvedCredentials is the outcome of the credentials lookup of a host, resolved once
+type resolvedCredentials struct {
+	once  sync.Once
+	creds Credentials
+	err   error
+}
+
+// DefaultDockerConfigPath returns the location of the Docker CLI configuration file,
+// honouring the DOCKER_CONFIG environment variable
+func DefaultDockerConfigPath() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".docker", "config.json")
+}
+
+// LoadDockerConfig reads the Docker CLI configuration file at path.
+// A missing file results in an empty configuration.
+// Credential helpers which cannot be run are reported to logger, which may be nil.
+func LoadDockerConfig(path string, logger *zap.SugaredLogger) (*DockerConfig, error) {
+	if logger == nil {
+		logger = zap.NewNop().Sugar()
+	}
+	config := &DockerConfig{logger: logger}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read docker config %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to decode docker config %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// Credentials returns the credentials stored for host.
+// Per-registry credential helpers take precedence over the global credential store,
+// which in turn takes precedence over the inline auths entries.
+// Each host is resolved once; lookups of different hosts run concurrently.
+func (c *DockerConfig) Credentials(host string) (Credentials, error) {
+	c.mu.Lock()
+	if c.cache == nil {
+		c.cache = make(map[string]*resolvedCredentials)
+	}
+	resolved, ok := c.cache[host]
+	if !ok {
+		resolved = &resolvedCredentials{}
+		c.cache[host] = resolved
+	}
+	c.mu.Unlock()
+
+	resolved.once.Do(func() {
+		resolved.creds, resolved.err = c.resolve(host)
+	})
+	return resolved.creds, resolved.err
+}
+
+// resolve looks up the credentials of host without caching
+func (c *DockerConfig) resolve(host string) (Credentials, error) {
+	keys := serverKeys(host)
+
+	for _, key := range keys {
+		if helper, ok := c.CredHelpers[key]; ok && helper != "" {
+			return c.helperCredentials(helper, helperServerURL(host, key))
+		}
+	}
+
+	if c.CredsStore != "" {
+		creds, err := c.helperCredentials(c.CredsStore, helperServerURL(host, host))
+		if err != nil || !creds.IsEmpty() {
+			return creds, err
+		}
+	}
+
+	for _, key := range keys {
+		if entry, ok := c.lookupAuth(key); ok {
+			return entry.credentials()
+		}
+	}
+
+	return Credentials{}, nil
+}
+
+// lookupAuth finds an auths entry whose key matches key once normalised
+func (c *DockerConfig) lookupAuth(key string) (dockerAuthConfig, bool) {
+	if entry, ok := c.Auths[key]; ok {
+		return entry, true
+	}
+
+	for server, entry := range c.Auths {
+		if normalizeHost(server) == key {
+			return entry, true
+		}
+	}
+
+	return dockerAuthConfig{}, false
+}
+
+// credentials decodes an auths entry
+func (a dockerAuthConfig) credentials() (Credentials, error) {
+	creds := Credentials{
+		Username:      a.Username,
+		Password:      a.Password,
+		IdentityToken: a.IdentityToken,
+		RegistryToken: a.RegistryToken,
+	}
+
+	if a.Auth != "" {
+		decoded, err := base64.StdEncoding.DecodeString(a.Auth)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("failed to decode auth entry: %w", err)
+		}
+		username, password, ok := strings.Cut(string(decoded), ":")
+		if !ok {
+			return Credentials{}, fmt.Errorf("invalid auth entry: missing ':' separator")
+		}
+		creds.Username = username
+		creds.Password = password
+	}
+
+	return creds, nil
+}
+
+// helperCredentials asks helper for the credentials of serverURL.
+// A helper which is not installed, fails or times out is reported and treated as holding no credentials,
+// so that images of public repositories can still be checked anonymously.
+func (c *DockerConfig) helperCredentials(helper, serverURL string) (Credentials, error) {
+	creds, err := getHelperCredentials(helper, serverURL)
+	var exitErr *exec.ExitError
+	if errors.Is(err, exec.ErrNotFound) || errors.As(err, &exitErr) || errors.Is(err, context.DeadlineExceeded) {
+		c.logger.Warnf("ignoring credential helper for %s: %v", serverURL, err)
+		return Credentials{}, nil
+	}
+	return creds, err
+}
+
+// getHelperCredentials runs `docker-credential-<helper> get` for serverURL
+func getHelperCredentials(helper, serverURL string) (Credentials, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), helperTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, credentialHelperPrefix+helper, "get")
+	cmd.Stdin = strings.NewReader(serverURL)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return Credentials{}, fmt.Errorf("credential helper %s timed out: %w", helper, ctx.Err())
+		}
+		output := strings.TrimSpace(stdout.String() + stderr.String())
+		// Helpers report missing entries on their output and exit with an error
+		if strings.Contains(strings.ToLower(output), "credentials not found") {
+			return Credentials{}, nil
+		}
+		return Credentials{}, fmt.Errorf("credential helper %s failed: %w (Output: %s)", helper, err, output)
+	}
+
+	var helperCreds helperCredentials
+	if err := json.Unmarshal(stdout.Bytes(), &helperCreds); err != nil {
+		return Credentials{}, fmt.Errorf("failed to decode credential helper %s output: %w", helper, err)
+	}
+
+	if helperCreds.Username == identityTokenUsername {
+		return Credentials{IdentityToken: helperCreds.Secret}, nil
+	}
+	return Credentials{Username: helperCreds.Username, Password: helperCreds.Secret}, nil
+}
+
+// serverKeys returns the normalised keys under which the credentials of host may be stored
+func serverKeys(host string) []string {
+	host = normalizeHost(host)
+	if isDockerHub(host) {
+		return []string{normalizeHost(dockerHubServerURL), "docker.io", "registry-1.docker.io"}
+	}
+	return []string{host}
+}
+
+// helperServerURL returns the server URL to ask credential helpers for
+func helperServerURL(host, key string) string {
+	if isDockerHub(normalizeHost(host)) {
+		return dockerHubServerURL
+	}
+	return key
+}
+
+// normalizeHost strips scheme and path from a server address (e.g. https://index.docker.io/v1/ -> index.docker.io)
+func normalizeHost(server string) string {
+	server = strings.TrimPrefix(server, "https://")
+	server = strings.TrimPrefix(server, "http://")
+	host, _, _ := strings.Cut(server, "/")
+	return strings.ToLower(host)
+}
+
+// isDockerHub reports whether host is one of the Docker Hub endpoints
+func isDockerHub(host string) bool {
+	return slices.Contains(dockerHubHosts, host)
+}
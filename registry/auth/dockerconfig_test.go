@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// writeDockerConfig writes a Docker CLI configuration file and loads it
+func writeDockerConfig(t *testing.T, content string) *DockerConfig {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	config, err := LoadDockerConfig(path, nil)
+	require.NoError(t, err)
+	return config
+}
+
+// installCredentialHelper creates a fake docker-credential-<name> executable printing output
+func installCredentialHelper(t *testing.T, name, script string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	helper := filepath.Join(dir, credentialHelperPrefix+name)
+	require.NoError(t, os.WriteFile(helper, []byte("#!/bin/sh\n"+script+"\n"), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestLoadDockerConfig_MissingFile(t *testing.T) {
+	config, err := LoadDockerConfig(filepath.Join(t.TempDir(), "missing.json"), nil)
+	require.NoError(t, err)
+
+	creds, err := config.Credentials("ghcr.io")
+	assert.NoError(t, err)
+	assert.True(t, creds.IsEmpty())
+}
+
+func TestLoadDockerConfig_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+
+	_, err := LoadDockerConfig(path, nil)
+	assert.Error(t, err)
+}
+
+func TestDefaultDockerConfigPath(t *testing.T) {
+	t.Setenv("DOCKER_CONFIG", "/opt/docker")
+	assert.Equal(t, "/opt/docker/config.json", DefaultDockerConfigPath())
+}
+
+func TestDockerConfig_Auths(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("hubuser:hubpass"))
+	config := writeDockerConfig(t, `{
+		"auths": {
+			"https://index.docker.io/v1/": {"auth": "`+auth+`"},
+			"registry.example.com:5000": {"username": "user", "password": "pass"},
+			"https://quay.io": {"identitytoken": "refresh"}
+		}
+	}`)
+
+	testCases := []struct {
+		host     string
+		expected Credentials
+	}{
+		{host: "docker.io", expected: Credentials{Username: "hubuser", Password: "hubpass"}},
+		{host: "registry-1.docker.io", expected: Credentials{Username: "hubuser", Password: "hubpass"}},
+		{host: "registry.hub.docker.com", expected: Credentials{Username: "hubuser", Password: "hubpass"}},
+		{host: "registry.example.com:5000", expected: Credentials{Username: "user", Password: "pass"}},
+		{host: "quay.io", expected: Credentials{IdentityToken: "refresh"}},
+		{host: "ghcr.io", expected: Credentials{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.host, func(t *testing.T) {
+			creds, err := config.Credentials(tc.host)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, creds)
+		})
+	}
+}
+
+func TestDockerConfig_InvalidAuth(t *testing.T) {
+	config := writeDockerConfig(t, `{"auths": {"ghcr.io": {"auth": "bm9zZXBhcmF0b3I="}}}`)
+
+	_, err := config.Credentials("ghcr.io")
+	assert.Error(t, err)
+}
+
+func TestDockerConfig_CredHelpers(t *testing.T) {
+	installCredentialHelper(t, "fake", `read server
+echo "{\"ServerURL\":\"$server\",\"Username\":\"helper-user\",\"Secret\":\"$server\"}"`)
+
+	config := writeDockerConfig(t, `{
+		"auths": {"ghcr.io": {"username": "ignored", "password": "ignored"}},
+		"credHelpers": {"ghcr.io": "fake", "docker.io": "fake"}
+	}`)
+
+	creds, err := config.Credentials("ghcr.io")
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{Username: "helper-user", Password: "ghcr.io"}, creds)
+
+	// Docker Hub is looked up with the server URL used by docker login
+	creds, err = config.Credentials("registry-1.docker.io")
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{Username: "helper-user", Password: dockerHubServerURL}, creds)
+}
+
+func TestDockerConfig_CredsStore(t *testing.T) {
+	installCredentialHelper(t, "store", `read server
+if [ "$server" = "quay.io" ]; then
+  echo '{"ServerURL":"quay.io","Username":"<token>","Secret":"identity"}'
+else
+  echo "credentials not found in native keychain"
+  exit 1
+fi`)
+
+	config := writeDockerConfig(t, `{
+		"auths": {"registry.example.com": {"username": "user", "password": "pass"}},
+		"credsStore": "store"
+	}`)
+
+	creds, err := config.Credentials("quay.io")
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{IdentityToken: "identity"}, creds)
+
+	// Hosts unknown to the store fall back to the auths entries
+	creds, err = config.Credentials("registry.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{Username: "user", Password: "pass"}, creds)
+}
+
+func TestDockerConfig_HelperFailure(t *testing.T) {
+	calls := filepath.Join(t.TempDir(), "calls")
+	installCredentialHelper(t, "broken", `echo call >> `+calls+`
+echo "keychain locked" >&2
+exit 1`)
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"auths": {"quay.io": {"username": "user", "password": "pass"}},
+		"credHelpers": {"ghcr.io": "broken", "registry.example.com": "missing"},
+		"credsStore": "broken"
+	}`), 0o600))
+	core, logs := observer.New(zap.WarnLevel)
+	config, err := LoadDockerConfig(path, zap.New(core).Sugar())
+	require.NoError(t, err)
+
+	// Failing helpers are treated as holding no credentials, so that public images can still be pulled
+	for range 2 {
+		creds, err := config.Credentials("ghcr.io")
+		assert.NoError(t, err)
+		assert.True(t, creds.IsEmpty())
+	}
+	data, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	assert.Equal(t, "call\n", string(data), "the outcome of the helper is cached")
+
+	creds, err := config.Credentials("registry.example.com")
+	assert.NoError(t, err)
+	assert.True(t, creds.IsEmpty())
+
+	// The inline auths entries are still used when the credential store fails
+	creds, err = config.Credentials("quay.io")
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{Username: "user", Password: "pass"}, creds)
+
+	require.Equal(t, 3, logs.Len())
+	assert.Contains(t, logs.All()[0].Message, "keychain locked")
+	assert.Contains(t, logs.All()[1].Message, "executable file not found")
+}
+
+func TestDockerConfig_HelperTimeout(t *testing.T) {
+	if testing.Short() {
+		t.Skip("waits for the credential helper timeout")
+	}
+	installCredentialHelper(t, "hanging", "exec sleep 60")
+
+	config := writeDockerConfig(t, `{"credHelpers": {"ghcr.io": "hanging"}}`)
+
+	creds, err := config.Credentials("ghcr.io")
+	assert.NoError(t, err)
+	assert.True(t, creds.IsEmpty())
+}
@@ -0,0 +1,421 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultTokenLifetime is used when the token server does not declare an expiration (as per the distribution spec)
+	defaultTokenLifetime = 60 * time.Second
+	// tokenExpiryMargin renews tokens slightly before they expire
+	tokenExpiryMargin = 5 * time.Second
+	// clientID identifies Chuck to token servers when exchanging refresh tokens
+	clientID = "chuck"
+)
+
+// Credentials holds the secrets used to authenticate against a registry
+type Credentials struct {
+	Username      string
+	Password      string
+	IdentityToken string // OAuth2 refresh token exchanged for access tokens
+	RegistryToken string // Bearer token sent to the registry as-is
+}
+
+// IsEmpty reports whether no secret is set
+func (c Credentials) IsEmpty() bool {
+	return c.Username == "" && c.Password == "" && c.IdentityToken == "" && c.RegistryToken == ""
+}
+
+// CredentialStore resolves the credentials to use for a registry host.
+// Stores return empty Credentials when nothing is configured for the host.
+type CredentialStore interface {
+	Credentials(host string) (Credentials, error)
+}
+
+// StaticCredentials is a CredentialStore returning the same credentials for every host
+type StaticCredentials Credentials
+
+// Credentials returns the static credentials
+func (s StaticCredentials) Credentials(string) (Credentials, error) {
+	return Credentials(s), nil
+}
+
+// tokenResponse represents the response of a registry token server
+type tokenResponse struct {
+	Token       string    `json:"token,omitempty"`
+	AccessToken string    `json:"access_token,omitempty"`
+	ExpiresIn   int       `json:"expires_in,omitempty"`
+	IssuedAt    time.Time `json:"issued_at,omitempty"`
+}
+
+// cachedToken is a bearer token along with its expiration
+type cachedToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+// Transport is an http.RoundTripper answering registry authentication challenges.
+// Requests receiving a 401 with a Bearer challenge are retried with a token fetched
+// from the challenge realm; tokens are cached per scope until they expire.
+// Basic challenges are answered with the configured username and password.
+type Transport struct {
+	base        http.RoundTripper
+	credentials CredentialStore
+	now         func() time.Time
+
+	mu         sync.Mutex
+	challenges map[string]Challenge   // Last challenge received, per host
+	tokens     map[string]cachedToken // Bearer tokens, per host and scope
+}
+
+// NewTransport creates a Transport wrapping base. credentials may be nil for anonymous access.
+func NewTransport(base http.RoundTripper, credentials CredentialStore) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{
+		base:        base,
+		credentials: credentials,
+		now:         time.Now,
+		challenges:  make(map[string]Challenge),
+		tokens:      make(map[string]cachedToken),
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Requests already carrying credentials are left untouched
+	if req.Header.Get("Authorization") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	host := req.URL.Host
+	scope := repositoryScope(req.URL.Path)
+
+	resp, err := t.send(req, host, scope)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// Requests with a body can only be replayed if it can be read again
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	challenge, ok := preferredChallenge(ParseChallenges(resp.Header.Get("WWW-Authenticate")))
+	if !ok {
+		return resp, nil
+	}
+	if challengeScope := challenge.Parameters["scope"]; challengeScope != "" {
+		scope = challengeScope
+	}
+
+	// A fresh challenge invalidates any token cached for the same scope
+	t.mu.Lock()
+	t.challenges[host] = challenge
+	delete(t.tokens, tokenKey(host, challenge, scope))
+	t.mu.Unlock()
+
+	authorization, err := t.authorization(req, challenge, scope)
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	if authorization == "" {
+		return resp, nil
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	retry := withAuthorization(req, authorization)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to replay request body: %w", err)
+		}
+		retry.Body = body
+	}
+
+	return t.base.RoundTrip(retry)
+}
+
+// send performs req, authenticating upfront when host already challenged a previous request
+func (t *Transport) send(req *http.Request, host, scope string) (*http.Response, error) {
+	challenge, ok := t.challenge(host)
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+
+	authorization, err := t.authorization(req, challenge, scope)
+	if err != nil {
+		return nil, err
+	}
+	if authorization == "" {
+		return t.base.RoundTrip(req)
+	}
+	return t.base.RoundTrip(withAuthorization(req, authorization))
+}
+
+// challenge returns the last challenge received from host
+func (t *Transport) challenge(host string) (Challenge, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	challenge, ok := t.challenges[host]
+	return challenge, ok
+}
+
+// authorization builds the Authorization header answering challenge.
+// It returns an empty string when the challenge cannot be answered.
+func (t *Transport) authorization(req *http.Request, challenge Challenge, scope string) (string, error) {
+	creds, err := t.lookupCredentials(req.URL.Host)
+	if err != nil {
+		return "", err
+	}
+
+	switch challenge.Scheme {
+	case "bearer":
+		if creds.RegistryToken != "" {
+			return "Bearer " + creds.RegistryToken, nil
+		}
+		token, err := t.token(req, challenge, scope, creds)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + token, nil
+	case "basic":
+		if creds.Username == "" && creds.Password == "" {
+			return "", nil
+		}
+		basicReq := &http.Request{Header: make(http.Header)}
+		basicReq.SetBasicAuth(creds.Username, creds.Password)
+		return basicReq.Header.Get("Authorization"), nil
+	}
+
+	return "", nil
+}
+
+// lookupCredentials returns the credentials configured for host, if any
+func (t *Transport) lookupCredentials(host string) (Credentials, error) {
+	if t.credentials == nil {
+		return Credentials{}, nil
+	}
+
+	creds, err := t.credentials.Credentials(host)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to resolve credentials for %s: %w", host, err)
+	}
+	return creds, nil
+}
+
+// token returns a cached bearer token for scope or fetches a new one from the challenge realm
+func (t *Transport) token(req *http.Request, challenge Challenge, scope string, creds Credentials) (string, error) {
+	key := tokenKey(req.URL.Host, challenge, scope)
+
+	t.mu.Lock()
+	cached, ok := t.tokens[key]
+	t.mu.Unlock()
+	if ok && t.now().Before(cached.expiresAt) {
+		return cached.value, nil
+	}
+
+	realm := challenge.Parameters["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("missing realm in authentication challenge from %s", req.URL.Host)
+	}
+
+	tokenReq, err := newTokenRequest(req, realm, challenge.Parameters["service"], scope, creds)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := t.base.RoundTrip(tokenReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to make HTTP request to token server: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("received non-OK status code from token server (%d): %s (Body: %s)", resp.StatusCode, resp.Status, string(respBody))
+	}
+
+	var tokenResp tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", fmt.Errorf("failed to decode token server response: %w", err)
+	}
+
+	token := tokenResp.Token
+	if token == "" {
+		token = tokenResp.AccessToken
+	}
+	if token == "" {
+		return "", fmt.Errorf("token server %s returned an empty token", realm)
+	}
+
+	lifetime := defaultTokenLifetime
+	if tokenResp.ExpiresIn > 0 {
+		lifetime = time.Duration(tokenResp.ExpiresIn) * time.Second
+	}
+	issuedAt := t.now()
+	if !tokenResp.IssuedAt.IsZero() && tokenResp.IssuedAt.Before(issuedAt) {
+		issuedAt = tokenResp.IssuedAt
+	}
+
+	t.mu.Lock()
+	t.tokens[key] = cachedToken{value: token, expiresAt: issuedAt.Add(lifetime - tokenExpiryMargin)}
+	t.mu.Unlock()
+
+	return token, nil
+}
+
+// newTokenRequest builds the request to the token server.
+// Refresh tokens use the OAuth2 POST flow, everything else the GET flow with optional basic auth.
+func newTokenRequest(req *http.Request, realm, service, scope string, creds Credentials) (*http.Request, error) {
+	realmURL, err := url.Parse(realm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token realm %q: %w", realm, err)
+	}
+
+	if creds.IdentityToken != "" {
+		form := url.Values{}
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", creds.IdentityToken)
+		form.Set("client_id", clientID)
+		if service != "" {
+			form.Set("service", service)
+		}
+		if scope != "" {
+			form.Set("scope", scope)
+		}
+
+		tokenReq, err := http.NewRequestWithContext(req.Context(), http.MethodPost, realmURL.String(), strings.NewReader(form.Encode()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP request to token server: %w", err)
+		}
+		tokenReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return tokenReq, nil
+	}
+
+	query := realmURL.Query()
+	if service != "" {
+		query.Set("service", service)
+	}
+	if scope != "" {
+		query.Set("scope", scope)
+	}
+	realmURL.RawQuery = query.Encode()
+
+	tokenReq, err := http.NewRequestWithContext(req.Context(), http.MethodGet, realmURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request to token server: %w", err)
+	}
+	if creds.Username != "" || creds.Password != "" {
+		tokenReq.SetBasicAuth(creds.Username, creds.Password)
+	}
+	return tokenReq, nil
+}
+
+// tokenKey identifies a cached token
+func tokenKey(host string, challenge Challenge, scope string) string {
+	return host + "|" + challenge.Parameters["service"] + "|" + scope
+}
+
+// preferredChallenge picks the Bearer challenge when offered, falling back to Basic
+func preferredChallenge(challenges []Challenge) (Challenge, bool) {
+	var basic *Challenge
+	for i, challenge := range challenges {
+		switch challenge.Scheme {
+		case "bearer":
+			return challenge, true
+		case "basic":
+			if basic == nil {
+				basic = &challenges[i]
+			}
+		}
+	}
+
+	if basic != nil {
+		return *basic, true
+	}
+	return Challenge{}, false
+}
+
+// repositoryScope derives the pull scope of a Distribution API path
+// (e.g. /v2/library/nginx/tags/list -> repository:library/nginx:pull).
+// The API may be served under a path prefix, as in /artifactory/api/docker/<repo>/v2/.
+func repositoryScope(requestPath string) string {
+	_, trimmed, ok := strings.Cut(requestPath, "/v2/")
+	if !ok {
+		return ""
+	}
+
+	for _, marker := range []string{"/tags/", "/manifests/", "/blobs/"} {
+		if idx := strings.LastIndex(trimmed, marker); idx > 0 {
+			return fmt.Sprintf("repository:%s:pull", trimmed[:idx])
+		}
+	}
+
+	return ""
+}
+
+// withAuthorization returns a copy of req with the Authorization header set
+func withAuthorization(req *http.Request, authorization string) *http.Request {
+	authReq := req.Clone(req.Context())
+	authReq.Header.Set("Authorization", authorization)
+	return authReq
+}
+
+// HostCredentials is a CredentialStore holding credentials by registry host
+type HostCredentials map[string]Credentials
+
+// Credentials returns the credentials configured for host, Docker Hub aliases included
+func (h HostCredentials) Credentials(host string) (Credentials, error) {
+	for _, key := range append([]string{host}, serverKeys(host)...) {
+		if creds, ok := h[key]; ok {
+			return creds, nil
+		}
+	}
+	return Credentials{}, nil
+}
+
+// ChainedCredentials queries each store in order and returns the first credentials found
+type ChainedCredentials []CredentialStore
+
+// Credentials returns the first non-empty credentials for host
+func (c ChainedCredentials) Credentials(host string) (Credentials, error) {
+	for _, store := range c {
+		creds, err := store.Credentials(host)
+		if err != nil {
+			return Credentials{}, err
+		}
+		if !creds.IsEmpty() {
+			return creds, nil
+		}
+	}
+	return Credentials{}, nil
+}
+
+// AliasCredentials resolves the credentials of Host whatever the host requested,
+// for registries reached through an endpoint other than their name (e.g. a Nexus port connector)
+type AliasCredentials struct {
+	Store CredentialStore
+	Host  string
+}
+
+// Credentials returns the credentials of the aliased host
+func (a AliasCredentials) Credentials(string) (Credentials, error) {
+	if a.Store == nil {
+		return Credentials{}, nil
+	}
+	return a.Store.Credentials(a.Host)
+}
@@ -1,14 +1,18 @@
 package dockerhub
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
 	"github.com/FedericoAntoniazzi/chuck/types"
+	"go.uber.org/zap"
 )
 
 // dockerHubListTagsResult represents the details of each query for repository tags
@@ -18,23 +22,96 @@ type dockerHubListTagResult struct {
 
 // dockerHubTagsResponses represents the response of Docker Hub when querying tags
 type dockerHubTagsResponse struct {
+	Count   int                      `json:"count,omitempty"`
+	Next    string                   `json:"next,omitempty"`
 	Results []dockerHubListTagResult `json:"results,omitempty"`
 }
 
 var dockerHubBaseURL string = "https://registry.hub.docker.com/v2"
 
+const (
+	// dockerHubPageSize is the number of tags requested for each page (the maximum allowed by Docker Hub)
+	dockerHubPageSize = 100
+	// DefaultMaxPages is the default number of pages fetched for a single repository
+	DefaultMaxPages = 10
+)
+
+// dockerHubLoginRequest represents the body sent to Docker Hub to obtain a JWT
+type dockerHubLoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// dockerHubLoginResponse represents the response of Docker Hub to a login request
+type dockerHubLoginResponse struct {
+	Token string `json:"token,omitempty"`
+}
+
 // Client is the DockerHub registry client
 type Client struct {
-	httpClient *http.Client
+	httpClient  *http.Client
+	credentials auth.CredentialStore
+	logger      *zap.SugaredLogger
+	maxPages    int
+
+	maxRetries     int
+	retryBaseDelay time.Duration
+	sleep          func(ctx context.Context, d time.Duration) error
+
+	mu       sync.Mutex
+	hubToken string // JWT obtained by logging in with the configured credentials
+
+	rateMu      sync.Mutex
+	rateLimit   RateLimit // Last quota reported by Docker Hub
+	rateLimited bool      // Set once Docker Hub refused requests, to stop querying it
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithCredentials sets the store resolving the Docker Hub credentials
+func WithCredentials(credentials auth.CredentialStore) Option {
+	return func(c *Client) {
+		c.credentials = credentials
+	}
+}
+
+// WithLogger sets the logger used to report pagination details
+func WithLogger(logger *zap.SugaredLogger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithMaxPages limits the number of pages fetched for a single repository.
+// Tags are ordered by last update, so the oldest tags are the ones left out.
+func WithMaxPages(maxPages int) Option {
+	return func(c *Client) {
+		if maxPages > 0 {
+			c.maxPages = maxPages
+		}
+	}
 }
 
 // NewClient creates and returns a new DockerHub client
-func NewClient() *Client {
-	return &Client{
-		httpClient: &http.Client{
-			Timeout: 15 * time.Second,
-		},
+func NewClient(opts ...Option) *Client {
+	client := &Client{
+		logger:         zap.NewNop().Sugar(),
+		maxPages:       DefaultMaxPages,
+		maxRetries:     defaultMaxRetries,
+		retryBaseDelay: defaultRetryBaseDelay,
+		sleep:          sleepContext,
+	}
+	for _, opt := range opts {
+		opt(client)
 	}
+
+	client.httpClient = &http.Client{
+		Timeout:   15 * time.Second,
+		Transport: auth.NewTransport(http.DefaultTransport, client.credentials),
+	}
+
+	return client
 }
 
 // GetTags fetches all available tags for a given image from Docker Hub
@@ -43,35 +120,197 @@ func (c *Client) GetTags(ctx context.Context, image types.Image) ([]string, erro
 		return nil, fmt.Errorf("unsupported url for Docker Hub: %s", image.Registry)
 	}
 
-	url := fmt.Sprintf("%s/namespaces/%s/repositories/%s/tags?page_size=200", dockerHubBaseURL, image.Namespace, image.Name)
+	pageURL := fmt.Sprintf("%s/namespaces/%s/repositories/%s/tags?page_size=%d&ordering=last_updated", dockerHubBaseURL, image.Namespace, image.Name, dockerHubPageSize)
+
+	var tags []string
+	pages := 0
+	for pageURL != "" {
+		if pages >= c.maxPages {
+			c.logger.Warnf("stopped listing tags for %s/%s after %d pages, older tags are ignored", image.Namespace, image.Name, pages)
+			break
+		}
+
+		tagsResponse, err := c.getTagsPage(ctx, pageURL)
+		if err != nil {
+			return nil, err
+		}
+		pages++
+
+		for _, tag := range tagsResponse.Results {
+			tags = append(tags, tag.Name)
+		}
+		pageURL = tagsResponse.Next
+	}
+
+	c.logger.Debugf("fetched %d tags for %s/%s from Docker Hub in %d pages", len(tags), image.Namespace, image.Name, pages)
+
+	return tags, nil
+}
+
+// getTagsPage fetches a single page of tags from Docker Hub.
+// Rate limited and failed requests are retried with a jittered exponential backoff.
+func (c *Client) getTagsPage(ctx context.Context, pageURL string) (*dockerHubTagsResponse, error) {
+	for attempt := 0; ; attempt++ {
+		if err := c.checkRateLimited(); err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP request to Docker Hub: %w", err)
+		}
+
+		if err := c.authorize(ctx, req); err != nil {
+			return nil, err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to make HTTP request to Docker Hub: %w", err)
+		}
+
+		c.recordRateLimit(resp.Header)
+
+		if resp.StatusCode == http.StatusOK {
+			defer func() { _ = resp.Body.Close() }()
+
+			var tagsResponse dockerHubTagsResponse
+			if err := json.NewDecoder(resp.Body).Decode(&tagsResponse); err != nil {
+				return nil, fmt.Errorf("failed to decode Docker Hub API response: %w", err)
+			}
+			return &tagsResponse, nil
+		}
+
+		respBody, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		statusErr := fmt.Errorf("received non-OK status code from Docker Hub (%d): %s (Body: %s)", resp.StatusCode, resp.Status, string(respBody))
+
+		if !isRetryable(resp.StatusCode) {
+			return nil, statusErr
+		}
+
+		delay := backoff(c.retryBaseDelay, attempt)
+		if wait, ok := retryAfter(resp.Header, time.Now()); ok {
+			delay = wait
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && (attempt >= c.maxRetries || delay > maxRetryDelay) {
+			c.rateMu.Lock()
+			c.rateLimited = true
+			c.rateMu.Unlock()
+			return nil, fmt.Errorf("%w, retry after %s: %w", ErrRateLimited, delay.Round(time.Second), statusErr)
+		}
+		if attempt >= c.maxRetries {
+			return nil, statusErr
+		}
+
+		c.logger.Debugf("retrying Docker Hub request in %s after status %d (attempt %d/%d)", delay, resp.StatusCode, attempt+1, c.maxRetries)
+		if err := c.sleep(ctx, delay); err != nil {
+			return nil, fmt.Errorf("interrupted while waiting to retry Docker Hub request: %w", err)
+		}
+	}
+}
+
+// RateLimit returns the last request quota reported by Docker Hub
+func (c *Client) RateLimit() RateLimit {
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+
+	return c.rateLimit
+}
+
+// checkRateLimited fails fast once Docker Hub refused requests or reported an exhausted quota
+func (c *Client) checkRateLimited() error {
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+
+	if c.rateLimited {
+		return fmt.Errorf("%w, skipping further requests", ErrRateLimited)
+	}
+	if c.rateLimit.Known() && c.rateLimit.Remaining <= 0 && time.Now().Before(c.rateLimit.Reset) {
+		return fmt.Errorf("%w, quota resets at %s", ErrRateLimited, c.rateLimit.Reset.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// recordRateLimit stores the quota reported in the response headers
+func (c *Client) recordRateLimit(header http.Header) {
+	rateLimit, ok := parseRateLimit(header)
+	if !ok {
+		return
+	}
+
+	c.rateMu.Lock()
+	c.rateLimit = rateLimit
+	c.rateMu.Unlock()
+
+	c.logger.Debugf("Docker Hub rate limit: %d/%d requests remaining", rateLimit.Remaining, rateLimit.Limit)
+	if rateLimit.Remaining*10 < rateLimit.Limit {
+		c.logger.Warnf("Docker Hub rate limit almost exhausted: %d/%d requests remaining", rateLimit.Remaining, rateLimit.Limit)
+	}
+}
+
+// authorize adds the Docker Hub JWT to req when credentials are configured,
+// so that private repositories can be listed
+func (c *Client) authorize(ctx context.Context, req *http.Request) error {
+	if c.credentials == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if c.hubToken == "" {
+		creds, err := c.credentials.Credentials("docker.io")
+		if err != nil {
+			return fmt.Errorf("failed to resolve Docker Hub credentials: %w", err)
+		}
+		if creds.Username == "" || creds.Password == "" {
+			return nil
+		}
+
+		token, err := c.login(ctx, creds)
+		if err != nil {
+			return err
+		}
+		c.hubToken = token
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.hubToken)
+	return nil
+}
+
+// login exchanges username and password (or personal access token) for a Docker Hub JWT
+func (c *Client) login(ctx context.Context, creds auth.Credentials) (string, error) {
+	body, err := json.Marshal(dockerHubLoginRequest{Username: creds.Username, Password: creds.Password})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request to Docker Hub: %w", err)
+		return "", fmt.Errorf("failed to encode Docker Hub login request: %w", err)
 	}
 
-	// TODO: Configure Auth for Docker Hub
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dockerHubBaseURL+"/users/login", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create login request to Docker Hub: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make HTTP request to Docker Hub: %w", err)
+		return "", fmt.Errorf("failed to log in to Docker Hub: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("received non-OK status code from Docker Hub (%d): %s (Body: %s)", resp.StatusCode, resp.Status, string(respBody))
+		return "", fmt.Errorf("received non-OK status code from Docker Hub login (%d): %s (Body: %s)", resp.StatusCode, resp.Status, string(respBody))
 	}
 
-	var tagsResponse dockerHubTagsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tagsResponse); err != nil {
-		return nil, fmt.Errorf("failed to decode Docker Hub API response: %w", err)
+	var loginResponse dockerHubLoginResponse
+	if err := json.NewDecoder(resp.Body).Decode(&loginResponse); err != nil {
+		return "", fmt.Errorf("failed to decode Docker Hub login response: %w", err)
 	}
-
-	tags := make([]string, len(tagsResponse.Results))
-	for i, tag := range tagsResponse.Results {
-		tags[i] = tag.Name
+	if loginResponse.Token == "" {
+		return "", fmt.Errorf("empty token returned by Docker Hub login")
 	}
 
-	return tags, nil
+	return loginResponse.Token, nil
 }
@@ -3,11 +3,13 @@ package dockerhub
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
 	"github.com/FedericoAntoniazzi/chuck/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -18,6 +20,7 @@ func TestNewClient(t *testing.T) {
 	assert.NotNil(t, client)
 	assert.NotNil(t, client.httpClient)
 	assert.Equal(t, 15*time.Second, client.httpClient.Timeout)
+	assert.Equal(t, DefaultMaxPages, client.maxPages)
 }
 
 // TestGetTags_UnsupportedRegistry tests the case where the image registry is not docker.io
@@ -77,6 +80,113 @@ func TestGetTags_Success(t *testing.T) {
 	assert.ElementsMatch(t, expectedTags, tags)
 }
 
+// newPaginatedServer starts a fake Docker Hub serving tags in pages of two
+func newPaginatedServer(t *testing.T, tags []string) *httptest.Server {
+	t.Helper()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "last_updated", r.URL.Query().Get("ordering"))
+
+		page := 1
+		if p := r.URL.Query().Get("page"); p != "" {
+			_, _ = fmt.Sscanf(p, "%d", &page)
+		}
+
+		start := (page - 1) * 2
+		end := min(start+2, len(tags))
+		response := dockerHubTagsResponse{Count: len(tags)}
+		for _, tag := range tags[start:end] {
+			response.Results = append(response.Results, dockerHubListTagResult{Name: tag})
+		}
+		if end < len(tags) {
+			response.Next = fmt.Sprintf("%s%s?page_size=2&ordering=last_updated&page=%d", server.URL, r.URL.Path, page+1)
+		}
+
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	originalDockerHubBaseURL := dockerHubBaseURL
+	dockerHubBaseURL = server.URL
+	t.Cleanup(func() { dockerHubBaseURL = originalDockerHubBaseURL })
+
+	return server
+}
+
+// TestGetTags_Pagination tests that next links are followed until exhausted
+func TestGetTags_Pagination(t *testing.T) {
+	expectedTags := []string{"1.29", "1.28", "1.27", "1.26", "1.25"}
+	newPaginatedServer(t, expectedTags)
+
+	client := NewClient()
+	image := types.Image{
+		Registry:  "docker.io",
+		Namespace: "library",
+		Name:      "nginx",
+	}
+
+	tags, err := client.GetTags(context.Background(), image)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedTags, tags)
+}
+
+// TestGetTags_MaxPages tests that pagination stops at the configured page cap
+func TestGetTags_MaxPages(t *testing.T) {
+	newPaginatedServer(t, []string{"1.29", "1.28", "1.27", "1.26", "1.25"})
+
+	client := NewClient(WithMaxPages(2))
+	image := types.Image{
+		Registry:  "docker.io",
+		Namespace: "library",
+		Name:      "nginx",
+	}
+
+	tags, err := client.GetTags(context.Background(), image)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.29", "1.28", "1.27", "1.26"}, tags)
+}
+
+// TestGetTags_Authenticated tests that credentials are exchanged for a JWT used to list private repositories
+func TestGetTags_Authenticated(t *testing.T) {
+	logins := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/users/login" {
+			logins++
+			var login dockerHubLoginRequest
+			_ = json.NewDecoder(r.Body).Decode(&login)
+			assert.Equal(t, dockerHubLoginRequest{Username: "user", Password: "pat"}, login)
+			_ = json.NewEncoder(w).Encode(dockerHubLoginResponse{Token: "jwt"})
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer jwt" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(dockerHubTagsResponse{Results: []dockerHubListTagResult{{Name: "1.0.0"}}})
+	}))
+	defer server.Close()
+
+	originalDockerHubBaseURL := dockerHubBaseURL
+	dockerHubBaseURL = server.URL
+	defer func() { dockerHubBaseURL = originalDockerHubBaseURL }()
+
+	client := NewClient(WithCredentials(auth.StaticCredentials{Username: "user", Password: "pat"}))
+	image := types.Image{
+		Registry:  "docker.io",
+		Namespace: "myuser",
+		Name:      "private",
+	}
+
+	for range 2 {
+		tags, err := client.GetTags(context.Background(), image)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"1.0.0"}, tags)
+	}
+	assert.Equal(t, 1, logins)
+}
+
 // TestGetTags_NonOKStatus tests when Docker Hub returns a non-200 status code
 func TestGetTags_NonOKStatus(t *testing.T) {
 	// Create a mock HTTP server that returns a 500 Internal Server Error
@@ -91,6 +201,8 @@ func TestGetTags_NonOKStatus(t *testing.T) {
 	defer func() { dockerHubBaseURL = originalDockerHubBaseURL }()
 
 	client := NewClient()
+	// Server errors are retried, skip the backoff delays
+	client.sleep = func(context.Context, time.Duration) error { return nil }
 	image := types.Image{
 		Registry:  "docker.io",
 		Namespace: "library",
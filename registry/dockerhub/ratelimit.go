@@ -0,0 +1,111 @@
+package dockerhub
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/registry"
+)
+
+const (
+	// defaultMaxRetries is the number of retries for rate limited or failed requests
+	defaultMaxRetries = 3
+	// defaultRetryBaseDelay is the delay before the first retry, doubled at each attempt
+	defaultRetryBaseDelay = 1 * time.Second
+	// maxRetryDelay is the longest wait accepted before giving up on a rate limited request
+	maxRetryDelay = 30 * time.Second
+)
+
+// ErrRateLimited is returned once Docker Hub refuses requests because the rate limit was exceeded
+var ErrRateLimited = fmt.Errorf("%w on Docker Hub", registry.ErrRateLimited)
+
+// RateLimit describes the request quota reported by Docker Hub
+type RateLimit struct {
+	Limit     int       // Requests allowed in the current window
+	Remaining int       // Requests left in the current window
+	Reset     time.Time // When the window resets, if reported
+}
+
+// Known reports whether Docker Hub sent rate limit headers
+func (r RateLimit) Known() bool {
+	return r.Limit > 0
+}
+
+// parseRateLimit reads the RateLimit-* (and X-RateLimit-*) headers of a response.
+// Values may carry a window suffix (e.g. "100;w=21600").
+func parseRateLimit(header http.Header) (RateLimit, bool) {
+	limit, hasLimit := headerInt(header, "RateLimit-Limit", "X-RateLimit-Limit")
+	remaining, hasRemaining := headerInt(header, "RateLimit-Remaining", "X-RateLimit-Remaining")
+	if !hasLimit || !hasRemaining {
+		return RateLimit{}, false
+	}
+
+	rateLimit := RateLimit{Limit: limit, Remaining: remaining}
+	if reset, ok := headerInt(header, "RateLimit-Reset", "X-RateLimit-Reset"); ok {
+		rateLimit.Reset = time.Unix(int64(reset), 0)
+	}
+	return rateLimit, true
+}
+
+// headerInt returns the integer value of the first header present among names
+func headerInt(header http.Header, names ...string) (int, bool) {
+	for _, name := range names {
+		value := header.Get(name)
+		if value == "" {
+			continue
+		}
+
+		value, _, _ = strings.Cut(value, ";")
+		parsed, err := strconv.Atoi(strings.TrimSpace(value))
+		if err == nil {
+			return parsed, true
+		}
+	}
+	return 0, false
+}
+
+// retryAfter parses the Retry-After header, expressed either in seconds or as an HTTP date
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// isRetryable reports whether a response status is worth retrying
+func isRetryable(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+// backoff returns the jittered exponential delay before retry number attempt (starting at 0)
+func backoff(base time.Duration, attempt int) time.Duration {
+	delay := base << attempt
+	// Equal jitter: wait between half and the whole delay
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,140 @@
+package dockerhub
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	header := http.Header{}
+	_, ok := parseRateLimit(header)
+	assert.False(t, ok)
+
+	header.Set("RateLimit-Limit", "100;w=21600")
+	header.Set("RateLimit-Remaining", "76;w=21600")
+	rateLimit, ok := parseRateLimit(header)
+	assert.True(t, ok)
+	assert.Equal(t, RateLimit{Limit: 100, Remaining: 76}, rateLimit)
+
+	header = http.Header{}
+	header.Set("X-RateLimit-Limit", "180")
+	header.Set("X-RateLimit-Remaining", "0")
+	header.Set("X-RateLimit-Reset", "1700000000")
+	rateLimit, ok = parseRateLimit(header)
+	assert.True(t, ok)
+	assert.Equal(t, RateLimit{Limit: 180, Remaining: 0, Reset: time.Unix(1700000000, 0)}, rateLimit)
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	header := http.Header{}
+	_, ok := retryAfter(header, now)
+	assert.False(t, ok)
+
+	header.Set("Retry-After", "120")
+	delay, ok := retryAfter(header, now)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, delay)
+
+	header.Set("Retry-After", now.Add(time.Hour).Format(http.TimeFormat))
+	delay, ok = retryAfter(header, now)
+	assert.True(t, ok)
+	assert.Equal(t, time.Hour, delay)
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := range 4 {
+		delay := backoff(time.Second, attempt)
+		assert.GreaterOrEqual(t, delay, (time.Second<<attempt)/2)
+		assert.LessOrEqual(t, delay, time.Second<<attempt)
+	}
+}
+
+// newRateLimitedClient points a client at handler and records the retry delays instead of sleeping
+func newRateLimitedClient(t *testing.T, handler http.HandlerFunc) (*Client, *[]time.Duration) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	originalDockerHubBaseURL := dockerHubBaseURL
+	dockerHubBaseURL = server.URL
+	t.Cleanup(func() { dockerHubBaseURL = originalDockerHubBaseURL })
+
+	var delays []time.Duration
+	client := NewClient()
+	client.sleep = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	return client, &delays
+}
+
+func TestGetTags_RetryServerErrors(t *testing.T) {
+	requests := 0
+	client, delays := newRateLimitedClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("RateLimit-Limit", "100;w=21600")
+		w.Header().Set("RateLimit-Remaining", "42;w=21600")
+		if requests < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(dockerHubTagsResponse{Results: []dockerHubListTagResult{{Name: "1.0"}}})
+	})
+
+	tags, err := client.GetTags(context.Background(), types.Image{Registry: "docker.io", Namespace: "library", Name: "nginx"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.0"}, tags)
+	assert.Equal(t, 3, requests)
+	assert.Len(t, *delays, 2)
+	assert.Equal(t, RateLimit{Limit: 100, Remaining: 42}, client.RateLimit())
+}
+
+func TestGetTags_RetryAfter(t *testing.T) {
+	requests := 0
+	client, delays := newRateLimitedClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "5")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(dockerHubTagsResponse{Results: []dockerHubListTagResult{{Name: "1.0"}}})
+	})
+
+	_, err := client.GetTags(context.Background(), types.Image{Registry: "docker.io", Namespace: "library", Name: "nginx"})
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Duration{5 * time.Second}, *delays)
+}
+
+func TestGetTags_RateLimitExceeded(t *testing.T) {
+	requests := 0
+	client, delays := newRateLimitedClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("RateLimit-Limit", "100;w=21600")
+		w.Header().Set("RateLimit-Remaining", "0;w=21600")
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	image := types.Image{Registry: "docker.io", Namespace: "library", Name: "nginx"}
+	_, err := client.GetTags(context.Background(), image)
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Contains(t, err.Error(), "retry after 1h0m0s")
+	// Waiting an hour is not worth it: give up without sleeping
+	assert.Empty(t, *delays)
+
+	// Further lookups fail fast without querying Docker Hub again
+	_, err = client.GetTags(context.Background(), image)
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Equal(t, 1, requests)
+}
@@ -0,0 +1,268 @@
+package ecr
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
+	"github.com/FedericoAntoniazzi/chuck/registry/oci"
+	"github.com/FedericoAntoniazzi/chuck/types"
+)
+
+// RegistryPattern matches the names of Amazon ECR private registries
+const RegistryPattern = "*.dkr.ecr.*.amazonaws.com"
+
+const (
+	// registryUsername is the username of ECR registry passwords
+	registryUsername = "AWS"
+	// getAuthorizationTokenTarget is the ECR API action issuing registry passwords
+	getAuthorizationTokenTarget = "AmazonEC2ContainerRegistry_V20150921.GetAuthorizationToken"
+	// tokenExpiryMargin renews registry passwords slightly before they expire
+	tokenExpiryMargin = 5 * time.Minute
+)
+
+// registryRegexp extracts the account and the region of an ECR registry name
+var registryRegexp = regexp.MustCompile(`^(\d{12})\.dkr\.ecr(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com$`)
+
+// authorizationTokenResponse represents the response of the ECR GetAuthorizationToken action
+type authorizationTokenResponse struct {
+	AuthorizationData []struct {
+		AuthorizationToken string  `json:"authorizationToken"` // base64 of AWS:<password>
+		ExpiresAt          float64 `json:"expiresAt"`          // Unix time
+	} `json:"authorizationData"`
+}
+
+// cachedPassword is a registry password along with its expiration
+type cachedPassword struct {
+	authorization string // Basic authorization header value
+	expiresAt     time.Time
+}
+
+// Client is the Amazon Elastic Container Registry client, serving every
+// <account>.dkr.ecr.<region>.amazonaws.com registry.
+// Registry passwords are obtained from the ECR API of the registry region with the access keys
+// configured as username (access key ID) and password (secret access key) of the registry,
+// with the session token as token, or read from the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY
+// and AWS_SESSION_TOKEN environment variables. Credentials with the AWS username, such as those
+// saved by docker login or the ECR credential helper, are used as registry passwords as-is.
+type Client struct {
+	credentials auth.CredentialStore
+	endpoint    string // Overrides the registry URL, for tests
+	apiEndpoint string // Overrides the ECR API URL, for tests
+	httpClient  *http.Client
+	getenv      func(string) string
+	now         func() time.Time
+
+	mu        sync.Mutex
+	clients   map[string]*oci.Client    // Distribution clients, per registry
+	passwords map[string]cachedPassword // Registry passwords, per registry
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithCredentials sets the store resolving the credentials of each registry
+func WithCredentials(credentials auth.CredentialStore) Option {
+	return func(c *Client) {
+		c.credentials = credentials
+	}
+}
+
+// WithEndpoint sends registry requests to endpoint instead of https://<registry>
+func WithEndpoint(endpoint string) Option {
+	return func(c *Client) {
+		c.endpoint = strings.TrimSuffix(endpoint, "/")
+	}
+}
+
+// WithAPIEndpoint sends ECR API requests to endpoint instead of https://api.ecr.<region>.amazonaws.com
+func WithAPIEndpoint(endpoint string) Option {
+	return func(c *Client) {
+		c.apiEndpoint = strings.TrimSuffix(endpoint, "/")
+	}
+}
+
+// NewClient creates and returns a new ECR client
+func NewClient(opts ...Option) *Client {
+	client := &Client{
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+		getenv:     os.Getenv,
+		now:        time.Now,
+		clients:    make(map[string]*oci.Client),
+		passwords:  make(map[string]cachedPassword),
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client
+}
+
+// GetTags fetches all available tags for a given image from its ECR registry
+func (c *Client) GetTags(ctx context.Context, image types.Image) ([]string, error) {
+	tags, _, _, err := c.GetTagsIfChanged(ctx, image, "")
+	return tags, err
+}
+
+// GetTagsIfChanged fetches all available tags for a given image unless they still match etag
+func (c *Client) GetTagsIfChanged(ctx context.Context, image types.Image, etag string) ([]string, string, bool, error) {
+	client, err := c.registryClient(image.Registry)
+	if err != nil {
+		return nil, "", false, err
+	}
+	return client.GetTagsIfChanged(ctx, image, etag)
+}
+
+// GetDigest returns the digest of the manifest currently published under the tag of image
+func (c *Client) GetDigest(ctx context.Context, image types.Image) (string, error) {
+	client, err := c.registryClient(image.Registry)
+	if err != nil {
+		return "", err
+	}
+	return client.GetDigest(ctx, image)
+}
+
+// registryClient returns the Distribution client of registry, authenticated with its password
+func (c *Client) registryClient(registry string) (*oci.Client, error) {
+	if !registryRegexp.MatchString(registry) {
+		return nil, fmt.Errorf("unsupported url for Amazon ECR: %s", registry)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if client, ok := c.clients[registry]; ok {
+		return client, nil
+	}
+
+	opts := []oci.Option{oci.WithTransport(&passwordTransport{client: c, registry: registry})}
+	if c.endpoint != "" {
+		opts = append(opts, oci.WithBaseURL(c.endpoint))
+	}
+	client := oci.NewClient(opts...)
+	c.clients[registry] = client
+	return client, nil
+}
+
+// authorization returns the Basic authorization of registry, requesting a new password
+// to the ECR API when the cached one expired
+func (c *Client) authorization(ctx context.Context, registry string) (string, error) {
+	c.mu.Lock()
+	cached, ok := c.passwords[registry]
+	c.mu.Unlock()
+	if ok && c.now().Before(cached.expiresAt) {
+		return cached.authorization, nil
+	}
+
+	var creds auth.Credentials
+	if c.credentials != nil {
+		var err error
+		creds, err = c.credentials.Credentials(registry)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve credentials for %s: %w", registry, err)
+		}
+	}
+
+	// Registry passwords (e.g. from aws ecr get-login-password) need no exchange
+	if creds.Username == registryUsername && creds.Password != "" {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(registryUsername+":"+creds.Password)), nil
+	}
+
+	keys := accessKeys{ID: creds.Username, Secret: creds.Password, SessionToken: creds.RegistryToken}
+	if keys.ID == "" && keys.Secret == "" {
+		keys = accessKeys{
+			ID:           c.getenv("AWS_ACCESS_KEY_ID"),
+			Secret:       c.getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken: c.getenv("AWS_SESSION_TOKEN"),
+		}
+	}
+	if keys.ID == "" || keys.Secret == "" {
+		return "", fmt.Errorf("missing AWS access keys for %s", registry)
+	}
+
+	password, err := c.getAuthorizationToken(ctx, registry, keys)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	c.passwords[registry] = password
+	c.mu.Unlock()
+
+	return password.authorization, nil
+}
+
+// getAuthorizationToken requests a password of registry to the ECR API
+func (c *Client) getAuthorizationToken(ctx context.Context, registry string, keys accessKeys) (cachedPassword, error) {
+	match := registryRegexp.FindStringSubmatch(registry)
+	account, region := match[1], match[2]
+
+	apiEndpoint := c.apiEndpoint
+	if apiEndpoint == "" {
+		apiEndpoint = fmt.Sprintf("https://api.ecr.%s.amazonaws.com", region)
+	}
+
+	body, err := json.Marshal(map[string][]string{"registryIds": {account}})
+	if err != nil {
+		return cachedPassword{}, fmt.Errorf("failed to encode ECR API request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiEndpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return cachedPassword{}, fmt.Errorf("failed to create HTTP request to ECR API: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", getAuthorizationTokenTarget)
+	signV4(req, body, keys, region, "ecr", c.now())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return cachedPassword{}, fmt.Errorf("failed to make HTTP request to ECR API: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return cachedPassword{}, fmt.Errorf("received non-OK status code from ECR API (%d): %s (Body: %s)", resp.StatusCode, resp.Status, string(respBody))
+	}
+
+	var tokenResp authorizationTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return cachedPassword{}, fmt.Errorf("failed to decode ECR API response: %w", err)
+	}
+	if len(tokenResp.AuthorizationData) == 0 || tokenResp.AuthorizationData[0].AuthorizationToken == "" {
+		return cachedPassword{}, fmt.Errorf("ECR API returned no authorization token for %s", registry)
+	}
+
+	data := tokenResp.AuthorizationData[0]
+	expiresAt := time.Unix(int64(data.ExpiresAt), 0).Add(-tokenExpiryMargin)
+	return cachedPassword{authorization: "Basic " + data.AuthorizationToken, expiresAt: expiresAt}, nil
+}
+
+// passwordTransport is an http.RoundTripper authenticating requests to an ECR registry
+type passwordTransport struct {
+	client   *Client
+	registry string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *passwordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	authorization, err := t.client.authorization(req.Context(), t.registry)
+	if err != nil {
+		return nil, err
+	}
+
+	authReq := req.Clone(req.Context())
+	authReq.Header.Set("Authorization", authorization)
+	return http.DefaultTransport.RoundTrip(authReq)
+}
@@ -0,0 +1,130 @@
+package ecr
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const registry = "123456789012.dkr.ecr.eu-west-1.amazonaws.com"
+
+var testNow = time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+// fakeECR is a fake ECR API and registry. The API issues the password "ecr-password"
+// to requests signed with the AKIAEXAMPLE access key.
+type fakeECR struct {
+	api      *httptest.Server
+	registry *httptest.Server
+	apiHits  atomic.Int32
+}
+
+func newFakeECR(t *testing.T) *fakeECR {
+	t.Helper()
+
+	f := &fakeECR{}
+	f.api = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.apiHits.Add(1)
+		assert.Equal(t, getAuthorizationTokenTarget, r.Header.Get("X-Amz-Target"))
+		assert.Equal(t, "20250701T120000Z", r.Header.Get("X-Amz-Date"))
+
+		var body map[string][]string
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, []string{"123456789012"}, body["registryIds"])
+
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIAEXAMPLE/20250701/eu-west-1/ecr/aws4_request, ") {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"__type":"UnrecognizedClientException"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"authorizationData": []map[string]any{{
+			"authorizationToken": base64.StdEncoding.EncodeToString([]byte("AWS:ecr-password")),
+			"expiresAt":          float64(testNow.Add(12 * time.Hour).Unix()),
+		}}})
+	}))
+	t.Cleanup(f.api.Close)
+
+	f.registry = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "AWS" || pass != "ecr-password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/v2/team/app/tags/list" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"name": "team/app", "tags": []string{"1.0.0", "1.1.0"}})
+	}))
+	t.Cleanup(f.registry.Close)
+
+	return f
+}
+
+func (f *fakeECR) client(opts ...Option) *Client {
+	client := NewClient(append([]Option{WithEndpoint(f.registry.URL), WithAPIEndpoint(f.api.URL)}, opts...)...)
+	client.now = func() time.Time { return testNow }
+	client.getenv = func(string) string { return "" }
+	return client
+}
+
+// TestGetTags_AccessKeys tests the password exchange with configured access keys, and its caching
+func TestGetTags_AccessKeys(t *testing.T) {
+	f := newFakeECR(t)
+	image := types.Image{Registry: registry, Namespace: "team", Name: "app"}
+
+	client := f.client(WithCredentials(auth.HostCredentials{registry: {Username: "AKIAEXAMPLE", Password: "secret"}}))
+	for range 2 {
+		tags, err := client.GetTags(context.Background(), image)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1.0.0", "1.1.0"}, tags)
+	}
+	assert.Equal(t, int32(1), f.apiHits.Load(), "passwords are reused until they expire")
+
+	_, err := f.client(WithCredentials(auth.HostCredentials{registry: {Username: "AKIAOTHER", Password: "secret"}})).GetTags(context.Background(), image)
+	assert.ErrorContains(t, err, "received non-OK status code from ECR API (403)")
+}
+
+// TestGetTags_Environment tests access keys read from the environment
+func TestGetTags_Environment(t *testing.T) {
+	f := newFakeECR(t)
+	image := types.Image{Registry: registry, Namespace: "team", Name: "app"}
+
+	client := f.client()
+	client.getenv = func(name string) string {
+		return map[string]string{"AWS_ACCESS_KEY_ID": "AKIAEXAMPLE", "AWS_SECRET_ACCESS_KEY": "secret"}[name]
+	}
+	tags, err := client.GetTags(context.Background(), image)
+	require.NoError(t, err)
+	assert.Len(t, tags, 2)
+
+	_, err = f.client().GetTags(context.Background(), image)
+	assert.ErrorContains(t, err, "missing AWS access keys")
+}
+
+// TestGetTags_RegistryPassword tests passwords saved by docker login, used without exchange
+func TestGetTags_RegistryPassword(t *testing.T) {
+	f := newFakeECR(t)
+
+	client := f.client(WithCredentials(auth.HostCredentials{registry: {Username: "AWS", Password: "ecr-password"}}))
+	tags, err := client.GetTags(context.Background(), types.Image{Registry: registry, Namespace: "team", Name: "app"})
+	require.NoError(t, err)
+	assert.Len(t, tags, 2)
+	assert.Equal(t, int32(0), f.apiHits.Load())
+}
+
+// TestGetTags_UnsupportedRegistry tests registries which are not ECR private registries
+func TestGetTags_UnsupportedRegistry(t *testing.T) {
+	_, err := NewClient().GetTags(context.Background(), types.Image{Registry: "public.ecr.aws", Namespace: "team", Name: "app"})
+	assert.ErrorContains(t, err, "unsupported url for Amazon ECR")
+}
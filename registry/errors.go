@@ -0,0 +1,7 @@
+package registry
+
+import "errors"
+
+// ErrRateLimited is wrapped by registry clients refusing further requests because
+// the registry rate limit was exceeded
+var ErrRateLimited = errors.New("rate limit exceeded")
@@ -0,0 +1,116 @@
+package ghcr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
+	"github.com/FedericoAntoniazzi/chuck/registry/oci"
+	"github.com/FedericoAntoniazzi/chuck/types"
+)
+
+// Registry is the name of the GitHub Container Registry in image references
+const Registry = "ghcr.io"
+
+var ghcrBaseURL string = "https://ghcr.io"
+
+// Client is the GitHub Container Registry client.
+// Public packages are listed with anonymous tokens, private ones with a personal access token
+// (PAT) configured as the password or token of ghcr.io.
+type Client struct {
+	oci         *oci.Client
+	httpClient  *http.Client // Client of the token endpoint
+	credentials auth.CredentialStore
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithCredentials sets the store resolving the ghcr.io credentials
+func WithCredentials(credentials auth.CredentialStore) Option {
+	return func(c *Client) {
+		c.credentials = credentials
+	}
+}
+
+// NewClient creates and returns a new GitHub Container Registry client
+func NewClient(opts ...Option) *Client {
+	client := &Client{
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	client.oci = oci.NewClient(
+		oci.WithBaseURL(ghcrBaseURL),
+		oci.WithTransport(auth.NewBearerTransport(http.DefaultTransport, auth.TokenSourceFunc(client.token))),
+	)
+
+	return client
+}
+
+// GetTags fetches all available tags for a given image from ghcr.io
+func (c *Client) GetTags(ctx context.Context, image types.Image) ([]string, error) {
+	tags, _, _, err := c.GetTagsIfChanged(ctx, image, "")
+	return tags, err
+}
+
+// GetTagsIfChanged fetches all available tags for a given image unless they still match etag
+func (c *Client) GetTagsIfChanged(ctx context.Context, image types.Image, etag string) ([]string, string, bool, error) {
+	if image.Registry != Registry {
+		return nil, "", false, fmt.Errorf("unsupported url for GitHub Container Registry: %s", image.Registry)
+	}
+	return c.oci.GetTagsIfChanged(ctx, image, etag)
+}
+
+// GetDigest returns the digest of the manifest currently published under the tag of image
+func (c *Client) GetDigest(ctx context.Context, image types.Image) (string, error) {
+	if image.Registry != Registry {
+		return "", fmt.Errorf("unsupported url for GitHub Container Registry: %s", image.Registry)
+	}
+	return c.oci.GetDigest(ctx, image)
+}
+
+// token exchanges the configured PAT, or nothing for public packages, for a registry token
+func (c *Client) token(ctx context.Context, scope string) (auth.Token, error) {
+	query := url.Values{}
+	query.Set("service", Registry)
+	query.Set("scope", scope)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ghcrBaseURL+"/token?"+query.Encode(), nil)
+	if err != nil {
+		return auth.Token{}, fmt.Errorf("failed to create HTTP request to ghcr.io token endpoint: %w", err)
+	}
+
+	if c.credentials != nil {
+		creds, err := c.credentials.Credentials(Registry)
+		if err != nil {
+			return auth.Token{}, fmt.Errorf("failed to resolve credentials for %s: %w", Registry, err)
+		}
+
+		pat := creds.Password
+		if pat == "" {
+			pat = creds.RegistryToken
+		}
+		if pat != "" {
+			username := creds.Username
+			if username == "" {
+				// ghcr.io only checks the PAT, any username is accepted
+				username = "chuck"
+			}
+			req.SetBasicAuth(username, pat)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return auth.Token{}, fmt.Errorf("failed to make HTTP request to ghcr.io token endpoint: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	return auth.ReadToken(resp, "ghcr.io token endpoint")
+}
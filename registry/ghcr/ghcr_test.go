@@ -0,0 +1,86 @@
+package ghcr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakeGHCR starts a fake ghcr.io serving two pages of tags for owner/app.
+// Tokens are only issued for the expected basic credentials, if any.
+func newFakeGHCR(t *testing.T, username, password string) {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			assert.Equal(t, "ghcr.io", r.URL.Query().Get("service"))
+			assert.Equal(t, "repository:owner/app:pull", r.URL.Query().Get("scope"))
+
+			user, pass, ok := r.BasicAuth()
+			if username != "" && (!ok || user != username || pass != password) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": "registry-token"})
+		case "/v2/owner/app/tags/list":
+			if r.Header.Get("Authorization") != "Bearer registry-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Query().Get("last") == "" {
+				w.Header().Set("Link", `</v2/owner/app/tags/list?n=100&last=1.1.0>; rel="next"`)
+				_ = json.NewEncoder(w).Encode(map[string]any{"name": "owner/app", "tags": []string{"1.0.0", "1.1.0"}})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": "owner/app", "tags": []string{"1.2.0", "latest"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	// Override the ghcrBaseURL for testing
+	originalBaseURL := ghcrBaseURL
+	ghcrBaseURL = server.URL
+	t.Cleanup(func() { ghcrBaseURL = originalBaseURL })
+}
+
+// TestGetTags_Anonymous tests the anonymous token exchange used for public packages
+func TestGetTags_Anonymous(t *testing.T) {
+	newFakeGHCR(t, "", "")
+
+	image := types.Image{Registry: "ghcr.io", Namespace: "owner", Name: "app"}
+	tags, err := NewClient().GetTags(context.Background(), image)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.0.0", "1.1.0", "1.2.0", "latest"}, tags)
+}
+
+// TestGetTags_PAT tests that the configured PAT is exchanged for a registry token
+func TestGetTags_PAT(t *testing.T) {
+	newFakeGHCR(t, "octocat", "ghp_secret")
+
+	image := types.Image{Registry: "ghcr.io", Namespace: "owner", Name: "app"}
+
+	client := NewClient(WithCredentials(auth.HostCredentials{"ghcr.io": {Username: "octocat", Password: "ghp_secret"}}))
+	tags, err := client.GetTags(context.Background(), image)
+	assert.NoError(t, err)
+	assert.Len(t, tags, 4)
+
+	client = NewClient(WithCredentials(auth.HostCredentials{"ghcr.io": {Username: "octocat", Password: "wrong"}}))
+	_, err = client.GetTags(context.Background(), image)
+	assert.ErrorContains(t, err, "401")
+}
+
+// TestGetTags_UnsupportedRegistry tests the case where the image registry is not ghcr.io
+func TestGetTags_UnsupportedRegistry(t *testing.T) {
+	tags, err := NewClient().GetTags(context.Background(), types.Image{Registry: "docker.io", Namespace: "library", Name: "nginx"})
+	assert.Nil(t, tags)
+	assert.ErrorContains(t, err, "unsupported url for GitHub Container Registry")
+}
@@ -0,0 +1,161 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
+	"github.com/FedericoAntoniazzi/chuck/registry/oci"
+	"github.com/FedericoAntoniazzi/chuck/types"
+)
+
+const (
+	// Registry is the name of the GitLab.com container registry in image references
+	Registry = "registry.gitlab.com"
+	// jobTokenUsername is the username going with CI job tokens
+	jobTokenUsername = "gitlab-ci-token"
+	// tokenService is the service of the registry tokens issued by GitLab
+	tokenService = "container_registry"
+)
+
+// Client is the client of a GitLab container registry.
+// Repositories are named after the full path of their project, nested groups included
+// (e.g. group/subgroup/project/app). Tokens are requested to the JWT endpoint of GitLab
+// (/jwt/auth) with a deploy token, a CI job token or a personal access token, or
+// anonymously for public projects.
+type Client struct {
+	host        string
+	endpoint    string
+	authURL     string
+	credentials auth.CredentialStore
+	httpClient  *http.Client // Client of the JWT endpoint
+	oci         *oci.Client
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithCredentials sets the store resolving the credentials of the registry host.
+// Deploy tokens are configured with their username and the token as password, job tokens
+// with the gitlab-ci-token username, or alone as token.
+func WithCredentials(credentials auth.CredentialStore) Option {
+	return func(c *Client) {
+		c.credentials = credentials
+	}
+}
+
+// WithEndpoint sets the URL of the registry, when it cannot be reached as https://<host>
+func WithEndpoint(endpoint string) Option {
+	return func(c *Client) {
+		if endpoint != "" {
+			c.endpoint = strings.TrimSuffix(endpoint, "/")
+		}
+	}
+}
+
+// WithAuthURL sets the URL of the GitLab JWT endpoint (e.g. https://gitlab.example.com/jwt/auth)
+func WithAuthURL(authURL string) Option {
+	return func(c *Client) {
+		if authURL != "" {
+			c.authURL = authURL
+		}
+	}
+}
+
+// NewClient creates and returns a new client of the GitLab registry named host.
+// Unless set with WithAuthURL, the JWT endpoint is looked up on the GitLab instance named
+// after the registry without its "registry." prefix (registry.gitlab.com -> gitlab.com).
+func NewClient(host string, opts ...Option) *Client {
+	client := &Client{
+		host:       host,
+		endpoint:   "https://" + host,
+		authURL:    "https://" + strings.TrimPrefix(host, "registry.") + "/jwt/auth",
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	client.oci = oci.NewClient(
+		oci.WithBaseURL(client.endpoint),
+		oci.WithTransport(auth.NewBearerTransport(http.DefaultTransport, auth.TokenSourceFunc(client.token))),
+	)
+
+	return client
+}
+
+// GetTags fetches all available tags for a given image from the GitLab registry
+func (c *Client) GetTags(ctx context.Context, image types.Image) ([]string, error) {
+	tags, _, _, err := c.GetTagsIfChanged(ctx, image, "")
+	return tags, err
+}
+
+// GetTagsIfChanged fetches all available tags for a given image unless they still match etag
+func (c *Client) GetTagsIfChanged(ctx context.Context, image types.Image, etag string) ([]string, string, bool, error) {
+	if err := c.checkImage(image); err != nil {
+		return nil, "", false, err
+	}
+	return c.oci.GetTagsIfChanged(ctx, image, etag)
+}
+
+// GetDigest returns the digest of the manifest currently published under the tag of image
+func (c *Client) GetDigest(ctx context.Context, image types.Image) (string, error) {
+	if err := c.checkImage(image); err != nil {
+		return "", err
+	}
+	return c.oci.GetDigest(ctx, image)
+}
+
+// checkImage ensures image is hosted on this registry and names its project
+func (c *Client) checkImage(image types.Image) error {
+	if image.Registry != c.host {
+		return fmt.Errorf("unsupported url for GitLab registry %s: %s", c.host, image.Registry)
+	}
+	if image.Namespace == "" || image.Namespace == "." {
+		return fmt.Errorf("missing GitLab project path in image %s (expected %s/<group>/<project>[/<name>])", image.Raw, c.host)
+	}
+	return nil
+}
+
+// token requests a registry token for scope to the GitLab JWT endpoint
+func (c *Client) token(ctx context.Context, scope string) (auth.Token, error) {
+	authURL, err := url.Parse(c.authURL)
+	if err != nil {
+		return auth.Token{}, fmt.Errorf("failed to parse GitLab JWT endpoint %q: %w", c.authURL, err)
+	}
+	query := authURL.Query()
+	query.Set("service", tokenService)
+	query.Set("scope", scope)
+	authURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, authURL.String(), nil)
+	if err != nil {
+		return auth.Token{}, fmt.Errorf("failed to create HTTP request to GitLab JWT endpoint: %w", err)
+	}
+
+	if c.credentials != nil {
+		creds, err := c.credentials.Credentials(c.host)
+		if err != nil {
+			return auth.Token{}, fmt.Errorf("failed to resolve credentials for %s: %w", c.host, err)
+		}
+
+		switch {
+		case creds.Username != "" || creds.Password != "":
+			req.SetBasicAuth(creds.Username, creds.Password)
+		case creds.RegistryToken != "":
+			req.SetBasicAuth(jobTokenUsername, creds.RegistryToken)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return auth.Token{}, fmt.Errorf("failed to make HTTP request to GitLab JWT endpoint: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	return auth.ReadToken(resp, "GitLab JWT endpoint")
+}
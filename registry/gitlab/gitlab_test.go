@@ -0,0 +1,100 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const host = "registry.gitlab.example.com"
+
+// newFakeGitLab starts a fake GitLab instance and its registry, serving the tags of the
+// private repository group/subgroup/project/app. Tokens are only issued to the deploy token
+// and to the CI job token.
+func newFakeGitLab(t *testing.T) (registry, gitlab *httptest.Server) {
+	t.Helper()
+
+	gitlab = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jwt/auth" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		assert.Equal(t, "container_registry", r.URL.Query().Get("service"))
+		assert.Equal(t, "repository:group/subgroup/project/app:pull", r.URL.Query().Get("scope"))
+
+		user, pass, ok := r.BasicAuth()
+		deployToken := user == "gitlab+deploy-token-1" && pass == "deploy-secret"
+		jobToken := user == "gitlab-ci-token" && pass == "job-secret"
+		if !ok || (!deployToken && !jobToken) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"token": "gitlab-jwt", "expires_in": 300})
+	}))
+	t.Cleanup(gitlab.Close)
+
+	registry = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/group/subgroup/project/app/tags/list" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer gitlab-jwt" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"name": "group/subgroup/project/app", "tags": []string{"2.0.0", "2.0.1", "main"}})
+	}))
+	t.Cleanup(registry.Close)
+
+	return registry, gitlab
+}
+
+// TestGetTags_NestedNamespace tests deploy and job tokens on a repository of a nested group
+func TestGetTags_NestedNamespace(t *testing.T) {
+	registry, gitlab := newFakeGitLab(t)
+	image := types.Image{Registry: host, Namespace: "group/subgroup/project", Name: "app"}
+
+	for name, creds := range map[string]auth.Credentials{
+		"deploy token":         {Username: "gitlab+deploy-token-1", Password: "deploy-secret"},
+		"job token":            {Username: "gitlab-ci-token", Password: "job-secret"},
+		"job token as a token": {RegistryToken: "job-secret"},
+	} {
+		client := NewClient(host,
+			WithEndpoint(registry.URL),
+			WithAuthURL(gitlab.URL+"/jwt/auth"),
+			WithCredentials(auth.HostCredentials{host: creds}),
+		)
+		tags, err := client.GetTags(context.Background(), image)
+		require.NoError(t, err, name)
+		assert.Equal(t, []string{"2.0.0", "2.0.1", "main"}, tags, name)
+	}
+
+	client := NewClient(host, WithEndpoint(registry.URL), WithAuthURL(gitlab.URL+"/jwt/auth"))
+	_, err := client.GetTags(context.Background(), image)
+	assert.ErrorContains(t, err, "received non-OK status code from GitLab JWT endpoint (401)")
+}
+
+// TestNewClient_AuthURL tests the JWT endpoint derived from the registry name
+func TestNewClient_AuthURL(t *testing.T) {
+	assert.Equal(t, "https://gitlab.com/jwt/auth", NewClient(Registry).authURL)
+	assert.Equal(t, "https://gitlab.example.com/jwt/auth", NewClient(host).authURL)
+	assert.Equal(t, "https://git.example.com:5050/jwt/auth", NewClient("git.example.com:5050").authURL)
+}
+
+// TestGetTags_InvalidImage tests images of other registries or without project path
+func TestGetTags_InvalidImage(t *testing.T) {
+	client := NewClient(Registry)
+
+	_, err := client.GetTags(context.Background(), types.Image{Registry: "docker.io", Namespace: "library", Name: "nginx"})
+	assert.ErrorContains(t, err, "unsupported url for GitLab registry registry.gitlab.com")
+
+	_, err = client.GetTags(context.Background(), types.Image{Raw: "registry.gitlab.com/app", Registry: Registry, Namespace: ".", Name: "app"})
+	assert.ErrorContains(t, err, "missing GitLab project path")
+}
@@ -0,0 +1,320 @@
+package google
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
+	"github.com/FedericoAntoniazzi/chuck/registry/oci"
+	"github.com/FedericoAntoniazzi/chuck/types"
+)
+
+// RegistryPatterns match the names of Google Container Registry and Artifact Registry hosts
+var RegistryPatterns = []string{"gcr.io", "*.gcr.io", "*-docker.pkg.dev"}
+
+const (
+	// JSONKeyUsername is the username going with a service account JSON key as password
+	JSONKeyUsername = "_json_key"
+	// jsonKeyBase64Username is the username going with a base64-encoded JSON key as password
+	jsonKeyBase64Username = "_json_key_base64"
+
+	// defaultTokenURL is the Google OAuth2 token endpoint, when the key does not name one
+	defaultTokenURL = "https://oauth2.googleapis.com/token"
+	// tokenScope is the OAuth2 scope requested for service accounts
+	tokenScope = "https://www.googleapis.com/auth/cloud-platform.read-only"
+	// jwtLifetime is the validity of the assertions signed with service account keys
+	jwtLifetime = time.Hour
+	// tokenExpiryMargin renews access tokens slightly before they expire
+	tokenExpiryMargin = time.Minute
+)
+
+// serviceAccountKey holds the fields of a service account JSON key used to obtain access tokens
+type serviceAccountKey struct {
+	ClientEmail  string `json:"client_email"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// cachedToken is an access token along with its expiration
+type cachedToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+// Client is the client of Google Container Registry (gcr.io) and Artifact Registry
+// (<location>-docker.pkg.dev) hosts. Private repositories are listed with the OAuth2 access
+// token of a service account, whose JSON key is configured as password with the _json_key
+// username, as for docker login. Any other username, such as oauth2accesstoken or the ones of
+// credential helpers, goes with an access token as password. Public repositories are listed anonymously.
+type Client struct {
+	credentials auth.CredentialStore
+	endpoint    string // Overrides the registry URL, for tests
+	httpClient  *http.Client
+	anonymous   http.RoundTripper
+	now         func() time.Time
+
+	mu      sync.Mutex
+	clients map[string]*oci.Client // Distribution clients, per registry
+	tokens  map[string]cachedToken // Access tokens, per service account
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithCredentials sets the store resolving the credentials of each registry
+func WithCredentials(credentials auth.CredentialStore) Option {
+	return func(c *Client) {
+		c.credentials = credentials
+	}
+}
+
+// WithEndpoint sends registry requests to endpoint instead of https://<registry>
+func WithEndpoint(endpoint string) Option {
+	return func(c *Client) {
+		c.endpoint = strings.TrimSuffix(endpoint, "/")
+	}
+}
+
+// NewClient creates and returns a new client of Google registries
+func NewClient(opts ...Option) *Client {
+	client := &Client{
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+		anonymous:  auth.NewTransport(http.DefaultTransport, nil),
+		now:        time.Now,
+		clients:    make(map[string]*oci.Client),
+		tokens:     make(map[string]cachedToken),
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client
+}
+
+// GetTags fetches all available tags for a given image from its Google registry
+func (c *Client) GetTags(ctx context.Context, image types.Image) ([]string, error) {
+	tags, _, _, err := c.GetTagsIfChanged(ctx, image, "")
+	return tags, err
+}
+
+// GetTagsIfChanged fetches all available tags for a given image unless they still match etag
+func (c *Client) GetTagsIfChanged(ctx context.Context, image types.Image, etag string) ([]string, string, bool, error) {
+	client, err := c.registryClient(image.Registry)
+	if err != nil {
+		return nil, "", false, err
+	}
+	return client.GetTagsIfChanged(ctx, image, etag)
+}
+
+// GetDigest returns the digest of the manifest currently published under the tag of image
+func (c *Client) GetDigest(ctx context.Context, image types.Image) (string, error) {
+	client, err := c.registryClient(image.Registry)
+	if err != nil {
+		return "", err
+	}
+	return client.GetDigest(ctx, image)
+}
+
+// IsRegistry reports whether registry is a Google Container Registry or Artifact Registry host
+func IsRegistry(registry string) bool {
+	for _, pattern := range RegistryPatterns {
+		if matched, _ := path.Match(pattern, registry); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// registryClient returns the Distribution client of registry
+func (c *Client) registryClient(registry string) (*oci.Client, error) {
+	if !IsRegistry(registry) {
+		return nil, fmt.Errorf("unsupported url for Google registries: %s", registry)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if client, ok := c.clients[registry]; ok {
+		return client, nil
+	}
+
+	opts := []oci.Option{oci.WithTransport(&tokenTransport{client: c, registry: registry})}
+	if c.endpoint != "" {
+		opts = append(opts, oci.WithBaseURL(c.endpoint))
+	}
+	client := oci.NewClient(opts...)
+	c.clients[registry] = client
+	return client, nil
+}
+
+// accessToken returns the access token authorizing requests to registry,
+// or an empty string when no credentials are configured
+func (c *Client) accessToken(ctx context.Context, registry string) (string, error) {
+	if c.credentials == nil {
+		return "", nil
+	}
+	creds, err := c.credentials.Credentials(registry)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve credentials for %s: %w", registry, err)
+	}
+
+	var keyJSON []byte
+	switch creds.Username {
+	case "":
+		return "", nil
+	case JSONKeyUsername:
+		keyJSON = []byte(creds.Password)
+	case jsonKeyBase64Username:
+		keyJSON, err = base64.StdEncoding.DecodeString(creds.Password)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode the base64 JSON key of %s: %w", registry, err)
+		}
+	default:
+		// oauth2accesstoken, and the usernames of credential helpers such as docker-credential-gcloud
+		// (_dcgcloud_token), go with an access token
+		return creds.Password, nil
+	}
+
+	var key serviceAccountKey
+	if err := json.Unmarshal(keyJSON, &key); err != nil {
+		return "", fmt.Errorf("failed to parse the service account key of %s: %w", registry, err)
+	}
+	return c.serviceAccountToken(ctx, key)
+}
+
+// serviceAccountToken returns a cached access token of the service account of key,
+// or exchanges a JWT signed with key for a new one
+func (c *Client) serviceAccountToken(ctx context.Context, key serviceAccountKey) (string, error) {
+	if key.ClientEmail == "" || key.PrivateKey == "" {
+		return "", errors.New("service account key misses client_email or private_key")
+	}
+
+	c.mu.Lock()
+	cached, ok := c.tokens[key.ClientEmail]
+	c.mu.Unlock()
+	if ok && c.now().Before(cached.expiresAt) {
+		return cached.value, nil
+	}
+
+	tokenURL := key.TokenURI
+	if tokenURL == "" {
+		tokenURL = defaultTokenURL
+	}
+
+	assertion, err := signJWT(key, tokenURL, c.now())
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	form.Set("assertion", assertion)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create HTTP request to Google token endpoint: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to make HTTP request to Google token endpoint: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	token, err := auth.ReadToken(resp, "Google token endpoint")
+	if err != nil {
+		return "", err
+	}
+
+	lifetime := token.ExpiresIn
+	if lifetime <= 0 {
+		lifetime = jwtLifetime
+	}
+	c.mu.Lock()
+	c.tokens[key.ClientEmail] = cachedToken{value: token.Value, expiresAt: c.now().Add(lifetime - tokenExpiryMargin)}
+	c.mu.Unlock()
+
+	return token.Value, nil
+}
+
+// signJWT returns the assertion requesting an access token for the service account of key
+func signJWT(key serviceAccountKey, audience string, now time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return "", errors.New("service account private key is not PEM encoded")
+	}
+
+	var privateKey *rsa.PrivateKey
+	if parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rsaKey, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return "", errors.New("service account private key is not an RSA key")
+		}
+		privateKey = rsaKey
+	} else if privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+		return "", fmt.Errorf("failed to parse service account private key: %w", err)
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": key.PrivateKeyID})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT header: %w", err)
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iss":   key.ClientEmail,
+		"scope": tokenScope,
+		"aud":   audience,
+		"iat":   now.Unix(),
+		"exp":   now.Add(jwtLifetime).Unix(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT claims: %w", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// tokenTransport is an http.RoundTripper authenticating requests to a Google registry
+// with an access token, or answering anonymous challenges when no credentials are configured
+type tokenTransport struct {
+	client   *Client
+	registry string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.client.accessToken(req.Context(), t.registry)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		return t.client.anonymous.RoundTrip(req)
+	}
+
+	authReq := req.Clone(req.Context())
+	authReq.Header.Set("Authorization", "Bearer "+token)
+	return http.DefaultTransport.RoundTrip(authReq)
+}
@@ -0,0 +1,180 @@
+package google
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const registry = "europe-west1-docker.pkg.dev"
+
+// fakeGoogle is a fake Google token endpoint and Artifact Registry. The token endpoint only
+// accepts assertions signed with the private key of chuck@project.iam.gserviceaccount.com,
+// and the registry serves public/app anonymously and private/app with the issued access token.
+type fakeGoogle struct {
+	oauth      *httptest.Server
+	registry   *httptest.Server
+	privateKey *rsa.PrivateKey
+	tokenHits  atomic.Int32
+}
+
+func newFakeGoogle(t *testing.T) *fakeGoogle {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	f := &fakeGoogle{privateKey: privateKey}
+
+	f.oauth = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.tokenHits.Add(1)
+		assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.FormValue("grant_type"))
+
+		parts := strings.Split(r.FormValue("assertion"), ".")
+		require.Len(t, parts, 3)
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if rsa.VerifyPKCS1v15(&privateKey.PublicKey, crypto.SHA256, digest[:], signature) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+
+		var claims map[string]any
+		payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(t, json.Unmarshal(payload, &claims))
+		assert.Equal(t, "chuck@project.iam.gserviceaccount.com", claims["iss"])
+		assert.Equal(t, f.oauth.URL+"/token", claims["aud"])
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "ya29.access", "expires_in": 3600, "token_type": "Bearer"})
+	}))
+	t.Cleanup(f.oauth.Close)
+
+	f.registry = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/token":
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": "anonymous"})
+		case "/v2/project/public/app/tags/list":
+			if r.Header.Get("Authorization") != "Bearer anonymous" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="`+f.registry.URL+`/v2/token",service="`+registry+`"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": "project/public/app", "tags": []string{"1.0.0"}})
+		case "/v2/project/private/app/tags/list":
+			if r.Header.Get("Authorization") != "Bearer ya29.access" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": "project/private/app", "tags": []string{"2.0.0", "2.1.0"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(f.registry.Close)
+
+	return f
+}
+
+// jsonKey returns a service account JSON key signing with privateKey
+func (f *fakeGoogle) jsonKey(t *testing.T, privateKey *rsa.PrivateKey) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	key, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"client_email":   "chuck@project.iam.gserviceaccount.com",
+		"private_key_id": "key-1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":      f.oauth.URL + "/token",
+	})
+	require.NoError(t, err)
+	return string(key)
+}
+
+// TestGetTags_ServiceAccount tests the exchange of a service account key for an access token
+func TestGetTags_ServiceAccount(t *testing.T) {
+	f := newFakeGoogle(t)
+	image := types.Image{Registry: registry, Namespace: "project/private", Name: "app"}
+
+	credentials := auth.HostCredentials{registry: {Username: JSONKeyUsername, Password: f.jsonKey(t, f.privateKey)}}
+	client := NewClient(WithEndpoint(f.registry.URL), WithCredentials(credentials))
+	for range 2 {
+		tags, err := client.GetTags(context.Background(), image)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2.0.0", "2.1.0"}, tags)
+	}
+	assert.Equal(t, int32(1), f.tokenHits.Load(), "access tokens are reused until they expire")
+
+	// The same key, base64-encoded
+	encoded := base64.StdEncoding.EncodeToString([]byte(f.jsonKey(t, f.privateKey)))
+	client = NewClient(WithEndpoint(f.registry.URL), WithCredentials(auth.HostCredentials{registry: {Username: "_json_key_base64", Password: encoded}}))
+	_, err := client.GetTags(context.Background(), image)
+	require.NoError(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	client = NewClient(WithEndpoint(f.registry.URL), WithCredentials(auth.HostCredentials{registry: {Username: JSONKeyUsername, Password: f.jsonKey(t, otherKey)}}))
+	_, err = client.GetTags(context.Background(), image)
+	assert.ErrorContains(t, err, "received non-OK status code from Google token endpoint (400)")
+}
+
+// TestGetTags_AccessToken tests access tokens configured as password
+func TestGetTags_AccessToken(t *testing.T) {
+	f := newFakeGoogle(t)
+
+	// Usernames other than the JSON key ones, like the one of docker-credential-gcloud, go with access tokens too
+	for _, username := range []string{"oauth2accesstoken", "_dcgcloud_token"} {
+		client := NewClient(WithEndpoint(f.registry.URL), WithCredentials(auth.HostCredentials{registry: {Username: username, Password: "ya29.access"}}))
+		tags, err := client.GetTags(context.Background(), types.Image{Registry: registry, Namespace: "project/private", Name: "app"})
+		require.NoError(t, err, username)
+		assert.Len(t, tags, 2, username)
+	}
+	assert.Equal(t, int32(0), f.tokenHits.Load())
+}
+
+// TestGetTags_Anonymous tests public repositories listed without credentials
+func TestGetTags_Anonymous(t *testing.T) {
+	f := newFakeGoogle(t)
+
+	client := NewClient(WithEndpoint(f.registry.URL))
+	tags, err := client.GetTags(context.Background(), types.Image{Registry: registry, Namespace: "project/public", Name: "app"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.0.0"}, tags)
+}
+
+// TestIsRegistry tests the registry names served by the client
+func TestIsRegistry(t *testing.T) {
+	for _, registry := range []string{"gcr.io", "eu.gcr.io", "us-docker.pkg.dev", "europe-west1-docker.pkg.dev"} {
+		assert.True(t, IsRegistry(registry), registry)
+	}
+	for _, registry := range []string{"docker.io", "gcr.io.example.com", "docker.pkg.dev", "europe-west1-maven.pkg.dev"} {
+		assert.False(t, IsRegistry(registry), registry)
+	}
+
+	_, err := NewClient().GetTags(context.Background(), types.Image{Registry: "docker.io", Namespace: "library", Name: "nginx"})
+	assert.ErrorContains(t, err, "unsupported url for Google registries")
+}
+
+// TestSignJWT_InvalidKey tests keys which cannot sign assertions
+func TestSignJWT_InvalidKey(t *testing.T) {
+	_, err := signJWT(serviceAccountKey{ClientEmail: "chuck@project", PrivateKey: "not a key"}, defaultTokenURL, time.Now())
+	assert.ErrorContains(t, err, "not PEM encoded")
+}
@@ -0,0 +1,93 @@
+package harbor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
+	"github.com/FedericoAntoniazzi/chuck/registry/oci"
+	"github.com/FedericoAntoniazzi/chuck/types"
+)
+
+// Client is the client of a Harbor registry.
+// Harbor repositories belong to projects, so images are referenced as <host>/<project>/<name>.
+// Robot accounts are configured with their full name (e.g. robot$project+ci) as username
+// and their secret as password, and exchanged for tokens by the Harbor token service.
+type Client struct {
+	host        string
+	endpoint    string
+	credentials auth.CredentialStore
+	oci         *oci.Client
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithCredentials sets the store resolving the credentials of the Harbor host
+func WithCredentials(credentials auth.CredentialStore) Option {
+	return func(c *Client) {
+		c.credentials = credentials
+	}
+}
+
+// WithEndpoint sets the URL of the Harbor instance (e.g. https://harbor.example.com:8443),
+// when it cannot be reached as https://<host>
+func WithEndpoint(endpoint string) Option {
+	return func(c *Client) {
+		if endpoint != "" {
+			c.endpoint = strings.TrimSuffix(endpoint, "/")
+		}
+	}
+}
+
+// NewClient creates and returns a new client of the Harbor registry named host
+func NewClient(host string, opts ...Option) *Client {
+	client := &Client{
+		host:     host,
+		endpoint: "https://" + host,
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	client.oci = oci.NewClient(
+		oci.WithBaseURL(client.endpoint),
+		oci.WithCredentials(auth.AliasCredentials{Store: client.credentials, Host: host}),
+	)
+
+	return client
+}
+
+// GetTags fetches all available tags for a given image from Harbor
+func (c *Client) GetTags(ctx context.Context, image types.Image) ([]string, error) {
+	tags, _, _, err := c.GetTagsIfChanged(ctx, image, "")
+	return tags, err
+}
+
+// GetTagsIfChanged fetches all available tags for a given image unless they still match etag
+func (c *Client) GetTagsIfChanged(ctx context.Context, image types.Image, etag string) ([]string, string, bool, error) {
+	if err := c.checkImage(image); err != nil {
+		return nil, "", false, err
+	}
+	return c.oci.GetTagsIfChanged(ctx, image, etag)
+}
+
+// GetDigest returns the digest of the manifest currently published under the tag of image
+func (c *Client) GetDigest(ctx context.Context, image types.Image) (string, error) {
+	if err := c.checkImage(image); err != nil {
+		return "", err
+	}
+	return c.oci.GetDigest(ctx, image)
+}
+
+// checkImage ensures image is hosted on this Harbor instance and names its project
+func (c *Client) checkImage(image types.Image) error {
+	if image.Registry != c.host {
+		return fmt.Errorf("unsupported url for Harbor registry %s: %s", c.host, image.Registry)
+	}
+	if image.Namespace == "" || image.Namespace == "." {
+		return fmt.Errorf("missing Harbor project in image %s (expected %s/<project>/<name>)", image.Raw, c.host)
+	}
+	return nil
+}
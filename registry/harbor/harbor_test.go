@@ -0,0 +1,86 @@
+package harbor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const robotName = "robot$library+chuck"
+
+// newFakeHarbor starts a fake Harbor serving the tags of the private project library/app.
+// Its token service only issues tokens to the robot account.
+func newFakeHarbor(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/service/token":
+			assert.Equal(t, "harbor-registry", r.URL.Query().Get("service"))
+			assert.Equal(t, "repository:library/app:pull", r.URL.Query().Get("scope"))
+
+			user, pass, ok := r.BasicAuth()
+			if !ok || user != robotName || pass != "robot-secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": "harbor-token"})
+		case "/v2/library/app/tags/list", "/v2/library/app/manifests/latest":
+			if r.Header.Get("Authorization") != "Bearer harbor-token" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/service/token",service="harbor-registry",scope="repository:library/app:pull"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.Method == http.MethodHead {
+				w.Header().Set("Docker-Content-Digest", "sha256:abc")
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": "library/app", "tags": []string{"1.0.0", "1.1.0", "latest"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// TestGetTags_RobotAccount tests that robot account credentials of the Harbor host are used
+// while requests go to the configured endpoint
+func TestGetTags_RobotAccount(t *testing.T) {
+	server := newFakeHarbor(t)
+
+	image := types.Image{Registry: "harbor.example.com", Namespace: "library", Name: "app", Tag: "latest"}
+	credentials := auth.HostCredentials{"harbor.example.com": {Username: robotName, Password: "robot-secret"}}
+	client := NewClient("harbor.example.com", WithEndpoint(server.URL+"/"), WithCredentials(credentials))
+
+	tags, err := client.GetTags(context.Background(), image)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.0.0", "1.1.0", "latest"}, tags)
+
+	digest, err := client.GetDigest(context.Background(), image)
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:abc", digest)
+
+	_, err = NewClient("harbor.example.com", WithEndpoint(server.URL)).GetTags(context.Background(), image)
+	assert.ErrorContains(t, err, "401")
+}
+
+// TestGetTags_InvalidImage tests images of other registries or without project
+func TestGetTags_InvalidImage(t *testing.T) {
+	client := NewClient("harbor.example.com")
+
+	_, err := client.GetTags(context.Background(), types.Image{Registry: "docker.io", Namespace: "library", Name: "nginx"})
+	assert.ErrorContains(t, err, "unsupported url for Harbor registry harbor.example.com")
+
+	_, err = client.GetTags(context.Background(), types.Image{Raw: "harbor.example.com/app:1.0", Registry: "harbor.example.com", Namespace: ".", Name: "app"})
+	assert.ErrorContains(t, err, "missing Harbor project")
+}
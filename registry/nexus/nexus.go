@@ -0,0 +1,96 @@
+package nexus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
+	"github.com/FedericoAntoniazzi/chuck/registry/oci"
+	"github.com/FedericoAntoniazzi/chuck/types"
+)
+
+// Client is the client of the Docker repositories of a Sonatype Nexus Repository instance.
+// A repository is reached either through its own port connector (set as endpoint, e.g.
+// https://nexus.example.com:8083), or through the Nexus URL when its name is set, in which
+// case the repository name is removed from images referenced as <host>/<repository>/<name>.
+type Client struct {
+	host        string
+	endpoint    string
+	repository  string
+	credentials auth.CredentialStore
+	oci         *oci.Client
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithCredentials sets the store resolving the credentials of the Nexus host
+func WithCredentials(credentials auth.CredentialStore) Option {
+	return func(c *Client) {
+		c.credentials = credentials
+	}
+}
+
+// WithEndpoint sets the URL of the Nexus instance or of a repository port connector,
+// when it cannot be reached as https://<host>
+func WithEndpoint(endpoint string) Option {
+	return func(c *Client) {
+		if endpoint != "" {
+			c.endpoint = strings.TrimSuffix(endpoint, "/")
+		}
+	}
+}
+
+// WithRepository queries the Docker repository named repository under /repository/<repository>/
+// of the endpoint
+func WithRepository(repository string) Option {
+	return func(c *Client) {
+		c.repository = strings.Trim(repository, "/")
+	}
+}
+
+// NewClient creates and returns a new client of the Nexus registry named host
+func NewClient(host string, opts ...Option) *Client {
+	client := &Client{
+		host:     host,
+		endpoint: "https://" + host,
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	baseURL := client.endpoint
+	if client.repository != "" {
+		baseURL += "/repository/" + client.repository
+	}
+
+	client.oci = oci.NewClient(
+		oci.WithBaseURL(baseURL),
+		oci.WithCredentials(auth.AliasCredentials{Store: client.credentials, Host: host}),
+	)
+
+	return client
+}
+
+// GetTags fetches all available tags for a given image from Nexus
+func (c *Client) GetTags(ctx context.Context, image types.Image) ([]string, error) {
+	tags, _, _, err := c.GetTagsIfChanged(ctx, image, "")
+	return tags, err
+}
+
+// GetTagsIfChanged fetches all available tags for a given image unless they still match etag
+func (c *Client) GetTagsIfChanged(ctx context.Context, image types.Image, etag string) ([]string, string, bool, error) {
+	if image.Registry != c.host {
+		return nil, "", false, fmt.Errorf("unsupported url for Nexus registry %s: %s", c.host, image.Registry)
+	}
+	return c.oci.GetTagsIfChanged(ctx, oci.TrimRepositoryPrefix(image, c.repository), etag)
+}
+
+// GetDigest returns the digest of the manifest currently published under the tag of image
+func (c *Client) GetDigest(ctx context.Context, image types.Image) (string, error) {
+	if image.Registry != c.host {
+		return "", fmt.Errorf("unsupported url for Nexus registry %s: %s", c.host, image.Registry)
+	}
+	return c.oci.GetDigest(ctx, oci.TrimRepositoryPrefix(image, c.repository))
+}
@@ -0,0 +1,78 @@
+package nexus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeNexus starts a fake Nexus serving the tags of team/app in the docker-hosted repository,
+// both on a port connector (/v2/) and under the repository path. Like Nexus without the Docker
+// Bearer Token Realm, it challenges anonymous requests with Basic authentication.
+func newFakeNexus(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/team/app/tags/list", "/repository/docker-hosted/v2/team/app/tags/list":
+			user, pass, ok := r.BasicAuth()
+			if !ok || user != "ci" || pass != "secret" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="Sonatype Nexus Repository Manager"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			// Nexus ignores n and returns every tag at once
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": "team/app", "tags": []string{"1.0.0", "2.0.0"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// TestGetTags_PortConnector tests a repository reached through its own port
+func TestGetTags_PortConnector(t *testing.T) {
+	server := newFakeNexus(t)
+
+	credentials := auth.HostCredentials{"nexus.example.com": {Username: "ci", Password: "secret"}}
+	client := NewClient("nexus.example.com", WithEndpoint(server.URL), WithCredentials(credentials))
+
+	tags, err := client.GetTags(context.Background(), types.Image{Registry: "nexus.example.com", Namespace: "team", Name: "app"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.0.0", "2.0.0"}, tags)
+}
+
+// TestGetTags_RepositoryPath tests a repository reached through the Nexus URL,
+// with images referenced with or without the repository name
+func TestGetTags_RepositoryPath(t *testing.T) {
+	server := newFakeNexus(t)
+
+	credentials := auth.HostCredentials{"nexus.example.com": {Username: "ci", Password: "secret"}}
+	client := NewClient("nexus.example.com", WithEndpoint(server.URL), WithRepository("docker-hosted"), WithCredentials(credentials))
+
+	for _, namespace := range []string{"docker-hosted/team", "team"} {
+		tags, err := client.GetTags(context.Background(), types.Image{Registry: "nexus.example.com", Namespace: namespace, Name: "app"})
+		require.NoError(t, err, namespace)
+		assert.Equal(t, []string{"1.0.0", "2.0.0"}, tags, namespace)
+	}
+
+	_, err := NewClient("nexus.example.com", WithEndpoint(server.URL), WithRepository("docker-hosted")).
+		GetTags(context.Background(), types.Image{Registry: "nexus.example.com", Namespace: "team", Name: "app"})
+	assert.ErrorContains(t, err, "401")
+}
+
+// TestGetTags_UnsupportedRegistry tests the case where the image is hosted on another registry
+func TestGetTags_UnsupportedRegistry(t *testing.T) {
+	tags, err := NewClient("nexus.example.com").GetTags(context.Background(), types.Image{Registry: "docker.io", Namespace: "library", Name: "nginx"})
+	assert.Nil(t, tags)
+	assert.ErrorContains(t, err, "unsupported url for Nexus registry nexus.example.com")
+}
@@ -0,0 +1,320 @@
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
+	"github.com/FedericoAntoniazzi/chuck/types"
+)
+
+// tagsListResponse represents the response of a Distribution v2 registry when listing tags
+type tagsListResponse struct {
+	Name string   `json:"name,omitempty"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+// registryScheme is the URL scheme used to reach registries
+var registryScheme string = "https"
+
+// registryHosts maps registry names to the host serving their Distribution API
+var registryHosts = map[string]string{
+	"docker.io": "registry-1.docker.io",
+}
+
+// manifestMediaTypes are the manifest formats accepted when resolving digests.
+// Manifest lists and indexes come first, as RepoDigests of multi-platform images refer to them.
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+const (
+	// defaultPageSize is the number of tags requested for each page (n parameter)
+	defaultPageSize = 100
+	// defaultMaxPages limits the number of pages fetched for a single repository
+	defaultMaxPages = 100
+)
+
+// Client is a generic client for registries implementing the OCI Distribution v2 API
+type Client struct {
+	httpClient  *http.Client
+	credentials auth.CredentialStore
+	transport   http.RoundTripper
+	baseURL     string
+	pageSize    int
+	maxPages    int
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithCredentials sets the store resolving the credentials of each registry
+func WithCredentials(credentials auth.CredentialStore) Option {
+	return func(c *Client) {
+		c.credentials = credentials
+	}
+}
+
+// WithTransport sets the transport used to reach registries, replacing the default
+// authentication answering WWW-Authenticate challenges with the configured credentials.
+// Clients of registries with their own token exchange use it to authenticate requests.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.transport = transport
+	}
+}
+
+// WithBaseURL sends every request to baseURL (e.g. https://registry.example.com)
+// instead of the registry of each image
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// NewClient creates and returns a new OCI Distribution client
+func NewClient(opts ...Option) *Client {
+	client := &Client{
+		pageSize: defaultPageSize,
+		maxPages: defaultMaxPages,
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	transport := client.transport
+	if transport == nil {
+		transport = auth.NewTransport(http.DefaultTransport, client.credentials)
+	}
+	client.httpClient = &http.Client{
+		Timeout:   15 * time.Second,
+		Transport: transport,
+	}
+
+	return client
+}
+
+// tagsPage is a single page of a tag listing
+type tagsPage struct {
+	tags        []string
+	nextURL     string // URL of the next page, empty on the last one
+	etag        string // ETag of the page, if the registry sends one
+	notModified bool   // Set when the registry answered 304 to a conditional request
+}
+
+// GetTags fetches all available tags for a given image from its registry,
+// following the pagination links returned by the registry
+func (c *Client) GetTags(ctx context.Context, image types.Image) ([]string, error) {
+	tags, _, _, err := c.GetTagsIfChanged(ctx, image, "")
+	return tags, err
+}
+
+// GetTagsIfChanged fetches all available tags for a given image unless the first page
+// of the listing still matches etag (If-None-Match), in which case modified is false.
+// An ETag is only returned for listings fitting in a single page: the ETag of the first page
+// does not change when tags are added to the following ones, which must then be fetched again.
+func (c *Client) GetTagsIfChanged(ctx context.Context, image types.Image, etag string) ([]string, string, bool, error) {
+	if image.Registry == "" {
+		return nil, "", false, fmt.Errorf("missing registry for image %s", image.Raw)
+	}
+
+	baseURL := c.registryURL(image)
+	pageURL := fmt.Sprintf("%s/v2/%s/tags/list?n=%d", baseURL, RepositoryPath(image), c.pageSize)
+
+	var tags []string
+	var firstETag string
+	for page := 0; pageURL != ""; page++ {
+		if page >= c.maxPages {
+			return nil, "", false, fmt.Errorf("too many pages listing tags for %s (limit: %d)", image.Raw, c.maxPages)
+		}
+
+		ifNoneMatch := ""
+		if page == 0 {
+			ifNoneMatch = etag
+		}
+
+		current, err := c.getTagsPage(ctx, pageURL, ifNoneMatch)
+		if err != nil {
+			return nil, "", false, err
+		}
+		if current.notModified {
+			return nil, etag, false, nil
+		}
+		if page == 0 {
+			firstETag = current.etag
+		}
+
+		// Registries not sending a Link header may still paginate with n/last.
+		// Ask for the next page when the current one is full, and stop as soon as
+		// the registry returns no new tags.
+		if current.nextURL == "" && len(current.tags) == c.pageSize {
+			last := current.tags[len(current.tags)-1]
+			if len(tags) == 0 || tags[len(tags)-1] != last {
+				current.nextURL = fmt.Sprintf("%s/v2/%s/tags/list?n=%d&last=%s", baseURL, RepositoryPath(image), c.pageSize, url.QueryEscape(last))
+			}
+		}
+
+		tags = append(tags, current.tags...)
+		pageURL = current.nextURL
+		if pageURL != "" {
+			firstETag = ""
+		}
+	}
+
+	return tags, firstETag, true, nil
+}
+
+// getTagsPage fetches a single page of tags, sending If-None-Match when etag is set
+func (c *Client) getTagsPage(ctx context.Context, pageURL string, etag string) (*tagsPage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request to registry: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make HTTP request to registry: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if etag != "" && resp.StatusCode == http.StatusNotModified {
+		return &tagsPage{notModified: true}, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("received non-OK status code from registry (%d): %s (Body: %s)", resp.StatusCode, resp.Status, string(respBody))
+	}
+
+	var tagsResponse tagsListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tagsResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode registry API response: %w", err)
+	}
+
+	nextURL, err := nextPageURL(req.URL, resp.Header.Get("Link"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &tagsPage{
+		tags:    tagsResponse.Tags,
+		nextURL: nextURL,
+		etag:    resp.Header.Get("ETag"),
+	}, nil
+}
+
+// GetDigest returns the digest of the manifest currently published under the tag of image
+// (e.g. sha256:...), read from the Docker-Content-Digest header of a HEAD request
+func (c *Client) GetDigest(ctx context.Context, image types.Image) (string, error) {
+	if image.Registry == "" {
+		return "", fmt.Errorf("missing registry for image %s", image.Raw)
+	}
+
+	manifestURL := fmt.Sprintf("%s/v2/%s/manifests/%s", c.registryURL(image), RepositoryPath(image), url.PathEscape(image.Tag))
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create HTTP request to registry: %w", err)
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to make HTTP request to registry: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("received non-OK status code from registry (%d): %s", resp.StatusCode, resp.Status)
+	}
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("registry did not return the digest of %s", image.Raw)
+	}
+	return digest, nil
+}
+
+// registryURL returns the base URL of the Distribution API serving image
+func (c *Client) registryURL(image types.Image) string {
+	if c.baseURL != "" {
+		return c.baseURL
+	}
+	return fmt.Sprintf("%s://%s", registryScheme, registryHost(image.Registry))
+}
+
+// registryHost returns the host serving the Distribution API of registry
+func registryHost(registry string) string {
+	if host, ok := registryHosts[registry]; ok {
+		return host
+	}
+	return registry
+}
+
+// RepositoryPath returns the repository name used by the Distribution API (e.g. library/nginx)
+func RepositoryPath(image types.Image) string {
+	if image.Namespace == "" || image.Namespace == "." {
+		return image.Name
+	}
+	return path.Join(image.Namespace, image.Name)
+}
+
+// nextPageURL extracts the next page from a Link header (e.g. </v2/foo/tags/list?n=2&last=b>; rel="next")
+// and resolves it against the URL of the current request
+func nextPageURL(current *url.URL, linkHeader string) (string, error) {
+	for _, link := range strings.Split(linkHeader, ",") {
+		parts := strings.Split(link, ";")
+		target := strings.TrimSpace(parts[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+
+		isNext := false
+		for _, param := range parts[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "rel") && strings.Trim(value, `"`) == "next" {
+				isNext = true
+			}
+		}
+		if !isNext {
+			continue
+		}
+
+		ref, err := url.Parse(strings.Trim(target, "<>"))
+		if err != nil {
+			return "", fmt.Errorf("failed to parse Link header %q: %w", linkHeader, err)
+		}
+		return current.ResolveReference(ref).String(), nil
+	}
+
+	return "", nil
+}
+
+// TrimRepositoryPrefix returns image with prefix removed from the front of its repository path
+// (e.g. docker-local/team/app -> team/app for the prefix docker-local), for registries routing
+// requests to a repository by the first path segments of image references.
+// Images not starting with prefix are returned unchanged.
+func TrimRepositoryPrefix(image types.Image, prefix string) types.Image {
+	prefix = strings.Trim(prefix, "/")
+	rest, ok := strings.CutPrefix(RepositoryPath(image), prefix+"/")
+	if prefix == "" || !ok {
+		return image
+	}
+
+	image.Namespace = path.Dir(rest)
+	image.Name = path.Base(rest)
+	return image
+}
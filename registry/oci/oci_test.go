@@ -0,0 +1,312 @@
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestRegistry starts a fake registry and points the client at it over plain HTTP
+func newTestRegistry(t *testing.T, handler http.HandlerFunc) string {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	originalScheme := registryScheme
+	registryScheme = "http"
+	t.Cleanup(func() { registryScheme = originalScheme })
+
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+// TestNewClient verifies that NewClient initializes the client correctly
+func TestNewClient(t *testing.T) {
+	client := NewClient()
+	assert.NotNil(t, client)
+	assert.NotNil(t, client.httpClient)
+	assert.Equal(t, 15*time.Second, client.httpClient.Timeout)
+	assert.Equal(t, defaultPageSize, client.pageSize)
+}
+
+// TestGetTags_MissingRegistry tests the case where the image has no registry
+func TestGetTags_MissingRegistry(t *testing.T) {
+	tags, err := NewClient().GetTags(context.Background(), types.Image{Name: "app"})
+	assert.Nil(t, tags)
+	assert.Error(t, err)
+}
+
+// TestGetTags_Success tests a single page listing
+func TestGetTags_Success(t *testing.T) {
+	host := newTestRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/v2/myorg/app/tags/list", r.URL.Path)
+		assert.Equal(t, "100", r.URL.Query().Get("n"))
+
+		_ = json.NewEncoder(w).Encode(tagsListResponse{Name: "myorg/app", Tags: []string{"1.0.0", "1.1.0", "latest"}})
+	})
+
+	image := types.Image{Registry: host, Namespace: "myorg", Name: "app"}
+	tags, err := NewClient().GetTags(context.Background(), image)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.0.0", "1.1.0", "latest"}, tags)
+}
+
+// TestGetTags_NoNamespace tests images pushed at the root of a registry
+func TestGetTags_NoNamespace(t *testing.T) {
+	host := newTestRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/app/tags/list", r.URL.Path)
+		_ = json.NewEncoder(w).Encode(tagsListResponse{Name: "app", Tags: []string{"1.0.0"}})
+	})
+
+	image := types.Image{Registry: host, Namespace: ".", Name: "app"}
+	tags, err := NewClient().GetTags(context.Background(), image)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.0.0"}, tags)
+}
+
+// TestGetTags_LinkPagination tests that Link headers are followed until exhausted
+func TestGetTags_LinkPagination(t *testing.T) {
+	pages := map[string][]string{
+		"":  {"a", "b"},
+		"b": {"c", "d"},
+		"d": {"e"},
+	}
+
+	host := newTestRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2", r.URL.Query().Get("n"))
+		last := r.URL.Query().Get("last")
+		tags := pages[last]
+
+		if last != "d" {
+			next := fmt.Sprintf("/v2/library/app/tags/list?n=2&last=%s", url.QueryEscape(tags[len(tags)-1]))
+			w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next))
+		}
+		_ = json.NewEncoder(w).Encode(tagsListResponse{Name: "library/app", Tags: tags})
+	})
+
+	client := NewClient()
+	client.pageSize = 2
+	tags, err := client.GetTags(context.Background(), types.Image{Registry: host, Namespace: "library", Name: "app"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, tags)
+}
+
+// TestGetTags_LastPagination tests registries paginating with n/last but without Link headers
+func TestGetTags_LastPagination(t *testing.T) {
+	pages := map[string][]string{
+		"":  {"a", "b"},
+		"b": {"c", "d"},
+		"d": {},
+	}
+
+	host := newTestRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(tagsListResponse{Name: "app", Tags: pages[r.URL.Query().Get("last")]})
+	})
+
+	client := NewClient()
+	client.pageSize = 2
+	tags, err := client.GetTags(context.Background(), types.Image{Registry: host, Name: "app"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, tags)
+}
+
+// TestGetTags_PageLimit tests that a registry returning endless pages is stopped
+func TestGetTags_PageLimit(t *testing.T) {
+	host := newTestRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", `</v2/app/tags/list?n=1&last=x>; rel="next"`)
+		_ = json.NewEncoder(w).Encode(tagsListResponse{Name: "app", Tags: []string{"x"}})
+	})
+
+	client := NewClient()
+	client.maxPages = 3
+	tags, err := client.GetTags(context.Background(), types.Image{Registry: host, Name: "app"})
+	assert.Nil(t, tags)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "too many pages")
+}
+
+// TestGetTags_BearerAuth tests registries requiring an anonymous bearer token
+func TestGetTags_BearerAuth(t *testing.T) {
+	var host string
+	host = newTestRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			assert.Equal(t, "repository:library/app:pull", r.URL.Query().Get("scope"))
+			_, _ = w.Write([]byte(`{"token":"anonymous","expires_in":300}`))
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer anonymous" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",service="fake"`, host))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(tagsListResponse{Name: "library/app", Tags: []string{"1.0.0"}})
+	})
+
+	tags, err := NewClient().GetTags(context.Background(), types.Image{Registry: host, Namespace: "library", Name: "app"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.0.0"}, tags)
+}
+
+// TestGetTagsIfChanged tests ETag revalidation of tag listings
+func TestGetTagsIfChanged(t *testing.T) {
+	host := newTestRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_ = json.NewEncoder(w).Encode(tagsListResponse{Name: "app", Tags: []string{"1.0.0"}})
+	})
+
+	client := NewClient()
+	image := types.Image{Registry: host, Name: "app"}
+
+	tags, etag, modified, err := client.GetTagsIfChanged(context.Background(), image, "")
+	assert.NoError(t, err)
+	assert.True(t, modified)
+	assert.Equal(t, `"v1"`, etag)
+	assert.Equal(t, []string{"1.0.0"}, tags)
+
+	tags, etag, modified, err = client.GetTagsIfChanged(context.Background(), image, `"v1"`)
+	assert.NoError(t, err)
+	assert.False(t, modified)
+	assert.Equal(t, `"v1"`, etag)
+	assert.Nil(t, tags)
+
+	tags, _, modified, err = client.GetTagsIfChanged(context.Background(), image, `"v0"`)
+	assert.NoError(t, err)
+	assert.True(t, modified)
+	assert.Equal(t, []string{"1.0.0"}, tags)
+}
+
+// TestGetTagsIfChanged_MultiplePages tests that paginated listings are not revalidated
+// with the ETag of their first page, which misses tags added to the following pages
+func TestGetTagsIfChanged_MultiplePages(t *testing.T) {
+	pages := map[string][]string{
+		"":    {"1.0", "2.0"},
+		"2.0": {"3.0"},
+	}
+
+	host := newTestRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		last := r.URL.Query().Get("last")
+		if last == "" {
+			if r.Header.Get("If-None-Match") == `"p0"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"p0"`)
+		}
+		_ = json.NewEncoder(w).Encode(tagsListResponse{Name: "app", Tags: pages[last]})
+	})
+
+	client := NewClient()
+	client.pageSize = 2
+	image := types.Image{Registry: host, Name: "app"}
+
+	tags, etag, modified, err := client.GetTagsIfChanged(context.Background(), image, "")
+	assert.NoError(t, err)
+	assert.True(t, modified)
+	assert.Empty(t, etag)
+	assert.Equal(t, []string{"1.0", "2.0", "3.0"}, tags)
+
+	// A tag pushed to the second page leaves the first one, and its ETag, unchanged
+	pages["2.0"] = []string{"3.0", "4.0"}
+	tags, etag, modified, err = client.GetTagsIfChanged(context.Background(), image, etag)
+	assert.NoError(t, err)
+	assert.True(t, modified)
+	assert.Empty(t, etag)
+	assert.Equal(t, []string{"1.0", "2.0", "3.0", "4.0"}, tags)
+}
+
+// TestGetTags_NonOKStatus tests when the registry returns a non-200 status code
+func TestGetTags_NonOKStatus(t *testing.T) {
+	host := newTestRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("repository not found"))
+	})
+
+	tags, err := NewClient().GetTags(context.Background(), types.Image{Registry: host, Name: "app"})
+	assert.Nil(t, tags)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "received non-OK status code from registry (404): 404 Not Found (Body: repository not found)")
+}
+
+func TestNextPageURL(t *testing.T) {
+	current, _ := url.Parse("https://registry.example.com/v2/app/tags/list?n=2")
+
+	testCases := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{name: "No header", header: "", expected: ""},
+		{name: "Relative link", header: `</v2/app/tags/list?n=2&last=b>; rel="next"`, expected: "https://registry.example.com/v2/app/tags/list?n=2&last=b"},
+		{name: "Absolute link", header: `<https://mirror.example.com/v2/app/tags/list?last=b>; rel=next`, expected: "https://mirror.example.com/v2/app/tags/list?last=b"},
+		{name: "Other relation", header: `</v2/app/tags/list?n=2>; rel="prev"`, expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := nextPageURL(current, tc.header)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+// TestGetDigest tests that the digest is read from a HEAD request on the manifest
+func TestGetDigest(t *testing.T) {
+	host := newTestRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodHead, r.Method)
+		assert.Equal(t, "/v2/myorg/app/manifests/latest", r.URL.Path)
+		assert.Contains(t, r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json")
+
+		w.Header().Set("Docker-Content-Digest", "sha256:0123")
+	})
+
+	image := types.Image{Registry: host, Namespace: "myorg", Name: "app", Tag: "latest"}
+	digest, err := NewClient().GetDigest(context.Background(), image)
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256:0123", digest)
+}
+
+// TestGetDigest_Errors tests missing manifests and digests
+func TestGetDigest_Errors(t *testing.T) {
+	host := newTestRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/myorg/missing/manifests/latest" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	_, err := NewClient().GetDigest(context.Background(), types.Image{Registry: host, Namespace: "myorg", Name: "missing", Tag: "latest"})
+	assert.ErrorContains(t, err, "404")
+
+	_, err = NewClient().GetDigest(context.Background(), types.Image{Registry: host, Namespace: "myorg", Name: "app", Tag: "latest"})
+	assert.ErrorContains(t, err, "did not return the digest")
+}
+
+// TestRegistryHost tests the mapping of Docker Hub to its Distribution API host
+func TestRegistryHost(t *testing.T) {
+	assert.Equal(t, "registry-1.docker.io", registryHost("docker.io"))
+	assert.Equal(t, "ghcr.io", registryHost("ghcr.io"))
+}
+
+// TestTrimRepositoryPrefix tests the removal of repository keys from image paths
+func TestTrimRepositoryPrefix(t *testing.T) {
+	image := types.Image{Registry: "art.example.com", Namespace: "docker-local/team", Name: "app"}
+	assert.Equal(t, "team/app", RepositoryPath(TrimRepositoryPrefix(image, "docker-local")))
+	assert.Equal(t, "app", RepositoryPath(TrimRepositoryPrefix(types.Image{Namespace: "docker-local", Name: "app"}, "docker-local/")))
+	assert.Equal(t, image, TrimRepositoryPrefix(image, "other"))
+	assert.Equal(t, image, TrimRepositoryPrefix(image, ""))
+}
@@ -0,0 +1,246 @@
+package quay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"go.uber.org/zap"
+)
+
+// Registry is the name of Quay.io in image references
+const Registry = "quay.io"
+
+// defaultEndpoint is the base URL of the Quay.io API
+const defaultEndpoint = "https://quay.io"
+
+const (
+	// quayPageSize is the number of tags requested for each page (the maximum allowed by Quay)
+	quayPageSize = 100
+	// DefaultMaxPages is the default number of pages fetched for a single repository
+	DefaultMaxPages = 10
+)
+
+// quayTag represents a tag returned by the Quay repository tag API
+type quayTag struct {
+	Name           string `json:"name"`
+	ManifestDigest string `json:"manifest_digest,omitempty"`
+	LastModified   string `json:"last_modified,omitempty"` // RFC 1123 with numeric zone
+	EndTS          int64  `json:"end_ts,omitempty"`        // Unix time the tag expires or expired at, if any
+}
+
+// quayTagsResponse represents a page of the Quay repository tag API
+type quayTagsResponse struct {
+	Tags          []quayTag `json:"tags"`
+	Page          int       `json:"page"`
+	HasAdditional bool      `json:"has_additional"`
+}
+
+// Client is the Quay.io registry client.
+// Tags are listed with the Quay repository tag API, which also reports when each tag was pushed.
+// Private repositories require an OAuth application token configured as the token of quay.io.
+type Client struct {
+	httpClient  *http.Client
+	credentials auth.CredentialStore
+	endpoint    string // Base URL of the Quay API
+	logger      *zap.SugaredLogger
+	maxPages    int
+	now         func() time.Time
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithCredentials sets the store resolving the quay.io credentials
+func WithCredentials(credentials auth.CredentialStore) Option {
+	return func(c *Client) {
+		c.credentials = credentials
+	}
+}
+
+// WithEndpoint sends API requests to endpoint instead of https://quay.io
+func WithEndpoint(endpoint string) Option {
+	return func(c *Client) {
+		if endpoint != "" {
+			c.endpoint = strings.TrimSuffix(endpoint, "/")
+		}
+	}
+}
+
+// WithLogger sets the logger used to report pagination details
+func WithLogger(logger *zap.SugaredLogger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithMaxPages limits the number of pages fetched for a single repository
+func WithMaxPages(maxPages int) Option {
+	return func(c *Client) {
+		if maxPages > 0 {
+			c.maxPages = maxPages
+		}
+	}
+}
+
+// NewClient creates and returns a new Quay.io client
+func NewClient(opts ...Option) *Client {
+	client := &Client{
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+		endpoint:   defaultEndpoint,
+		logger:     zap.NewNop().Sugar(),
+		maxPages:   DefaultMaxPages,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client
+}
+
+// GetTags fetches all active tags for a given image from Quay.io
+func (c *Client) GetTags(ctx context.Context, image types.Image) ([]string, error) {
+	infos, err := c.GetTagInfos(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]string, len(infos))
+	for i, info := range infos {
+		tags[i] = info.Name
+	}
+	return tags, nil
+}
+
+// GetTagInfos fetches all active tags for a given image from Quay.io,
+// along with their last modification time and manifest digest.
+// Expired tags are left out.
+func (c *Client) GetTagInfos(ctx context.Context, image types.Image) ([]types.TagInfo, error) {
+	if image.Registry != Registry {
+		return nil, fmt.Errorf("unsupported url for Quay: %s", image.Registry)
+	}
+
+	repository := repositoryPath(image)
+
+	var infos []types.TagInfo
+	page := 1
+	for {
+		if page > c.maxPages {
+			c.logger.Warnf("stopped listing tags for %s after %d pages, older tags are ignored", repository, c.maxPages)
+			break
+		}
+
+		tagsResponse, err := c.getTagsPage(ctx, repository, page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tag := range tagsResponse.Tags {
+			if tag.EndTS != 0 && !c.now().Before(time.Unix(tag.EndTS, 0)) {
+				continue
+			}
+
+			info := types.TagInfo{Name: tag.Name, Digest: tag.ManifestDigest}
+			if modified, err := time.Parse(time.RFC1123Z, tag.LastModified); err == nil {
+				info.LastModified = modified.UTC()
+			}
+			infos = append(infos, info)
+		}
+
+		if !tagsResponse.HasAdditional {
+			break
+		}
+		page++
+	}
+
+	c.logger.Debugf("fetched %d tags for %s from Quay", len(infos), repository)
+
+	return infos, nil
+}
+
+// GetDigest returns the manifest digest currently published under the tag of image,
+// read from the Quay repository tag API with the same token as tag listings
+func (c *Client) GetDigest(ctx context.Context, image types.Image) (string, error) {
+	if image.Registry != Registry {
+		return "", fmt.Errorf("unsupported url for Quay: %s", image.Registry)
+	}
+
+	query := url.Values{}
+	query.Set("onlyActiveTags", "true")
+	query.Set("specificTag", image.Tag)
+	tagsResponse, err := c.queryTags(ctx, repositoryPath(image), query)
+	if err != nil {
+		return "", err
+	}
+
+	for _, tag := range tagsResponse.Tags {
+		if tag.Name == image.Tag && tag.ManifestDigest != "" {
+			return tag.ManifestDigest, nil
+		}
+	}
+	return "", fmt.Errorf("quay.io did not return the digest of %s", image.Raw)
+}
+
+// repositoryPath returns the path of the repository of image in the Quay API
+func repositoryPath(image types.Image) string {
+	if image.Namespace != "" {
+		return image.Namespace + "/" + image.Name
+	}
+	return image.Name
+}
+
+// getTagsPage fetches a single page of active tags from the Quay repository tag API
+func (c *Client) getTagsPage(ctx context.Context, repository string, page int) (*quayTagsResponse, error) {
+	query := url.Values{}
+	query.Set("onlyActiveTags", "true")
+	query.Set("limit", strconv.Itoa(quayPageSize))
+	query.Set("page", strconv.Itoa(page))
+	return c.queryTags(ctx, repository, query)
+}
+
+// queryTags queries the Quay repository tag API of repository
+func (c *Client) queryTags(ctx context.Context, repository string, query url.Values) (*quayTagsResponse, error) {
+	pageURL := fmt.Sprintf("%s/api/v1/repository/%s/tag/?%s", c.endpoint, repository, query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request to Quay: %w", err)
+	}
+
+	if c.credentials != nil {
+		creds, err := c.credentials.Credentials(Registry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve credentials for %s: %w", Registry, err)
+		}
+		// Robot account passwords are only valid for the registry, the API requires an OAuth token
+		if creds.RegistryToken != "" {
+			req.Header.Set("Authorization", "Bearer "+creds.RegistryToken)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make HTTP request to Quay: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("received non-OK status code from Quay (%d): %s (Body: %s)", resp.StatusCode, resp.Status, string(respBody))
+	}
+
+	var tagsResponse quayTagsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tagsResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode Quay API response: %w", err)
+	}
+	return &tagsResponse, nil
+}
@@ -0,0 +1,137 @@
+package quay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/FedericoAntoniazzi/chuck/registry/auth"
+	"github.com/FedericoAntoniazzi/chuck/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+// newFakeQuay starts a fake Quay serving two pages of tags for nginx/nginx-unprivileged.
+// Requests must carry the expected bearer token, if any. It returns the URL of the fake.
+func newFakeQuay(t *testing.T, token string) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repository/nginx/nginx-unprivileged/tag/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if token != "" && r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "true", r.URL.Query().Get("onlyActiveTags"))
+
+		if specificTag := r.URL.Query().Get("specificTag"); specificTag != "" {
+			tags := []quayTag{}
+			if specificTag == "1.27" {
+				tags = append(tags, quayTag{Name: "1.27", ManifestDigest: "sha256:127"})
+			}
+			_ = json.NewEncoder(w).Encode(quayTagsResponse{Page: 1, Tags: tags})
+			return
+		}
+
+		if r.URL.Query().Get("page") == "1" {
+			_ = json.NewEncoder(w).Encode(quayTagsResponse{Page: 1, HasAdditional: true, Tags: []quayTag{
+				{Name: "1.27", ManifestDigest: "sha256:127", LastModified: "Tue, 10 Jun 2025 08:30:00 -0000"},
+				{Name: "1.26", ManifestDigest: "sha256:126", LastModified: "Mon, 05 May 2025 10:00:00 -0000"},
+			}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(quayTagsResponse{Page: 2, Tags: []quayTag{
+			{Name: "1.25", ManifestDigest: "sha256:125", LastModified: "Tue, 04 Mar 2025 10:00:00 -0000"},
+			{Name: "1.25-old", LastModified: "Tue, 04 Mar 2025 10:00:00 -0000", EndTS: testNow.Add(-time.Hour).Unix()},
+			{Name: "1.24", LastModified: "Tue, 04 Feb 2025 10:00:00 -0000", EndTS: testNow.Add(time.Hour).Unix()},
+		}})
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+func newTestClient(endpoint string, opts ...Option) *Client {
+	client := NewClient(append([]Option{WithEndpoint(endpoint)}, opts...)...)
+	client.now = func() time.Time { return testNow }
+	return client
+}
+
+// TestGetTagInfos tests pagination, timestamps and the removal of expired tags
+func TestGetTagInfos(t *testing.T) {
+	endpoint := newFakeQuay(t, "")
+
+	image := types.Image{Registry: "quay.io", Namespace: "nginx", Name: "nginx-unprivileged"}
+	infos, err := newTestClient(endpoint).GetTagInfos(context.Background(), image)
+	require.NoError(t, err)
+	require.Len(t, infos, 4)
+
+	assert.Equal(t, types.TagInfo{
+		Name:         "1.27",
+		Digest:       "sha256:127",
+		LastModified: time.Date(2025, 6, 10, 8, 30, 0, 0, time.UTC),
+	}, infos[0])
+	assert.Equal(t, "1.24", infos[3].Name, "tags expiring in the future are still active")
+
+	tags, err := newTestClient(endpoint).GetTags(context.Background(), image)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.27", "1.26", "1.25", "1.24"}, tags)
+}
+
+// TestGetTagInfos_MaxPages tests that pagination stops after the configured number of pages
+func TestGetTagInfos_MaxPages(t *testing.T) {
+	endpoint := newFakeQuay(t, "")
+
+	image := types.Image{Registry: "quay.io", Namespace: "nginx", Name: "nginx-unprivileged"}
+	tags, err := newTestClient(endpoint, WithMaxPages(1)).GetTags(context.Background(), image)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.27", "1.26"}, tags)
+}
+
+// TestGetTagInfos_Token tests that the configured OAuth token is sent to the API
+func TestGetTagInfos_Token(t *testing.T) {
+	endpoint := newFakeQuay(t, "oauth-token")
+
+	image := types.Image{Registry: "quay.io", Namespace: "nginx", Name: "nginx-unprivileged"}
+
+	client := newTestClient(endpoint, WithCredentials(auth.HostCredentials{"quay.io": {RegistryToken: "oauth-token"}}))
+	tags, err := client.GetTags(context.Background(), image)
+	assert.NoError(t, err)
+	assert.Len(t, tags, 4)
+
+	_, err = newTestClient(endpoint).GetTags(context.Background(), image)
+	assert.ErrorContains(t, err, "received non-OK status code from Quay (401)")
+}
+
+// TestGetDigest tests that digests are read from the tag API, with the configured OAuth token
+func TestGetDigest(t *testing.T) {
+	endpoint := newFakeQuay(t, "oauth-token")
+
+	client := newTestClient(endpoint, WithCredentials(auth.HostCredentials{"quay.io": {RegistryToken: "oauth-token"}}))
+	image := types.Image{Raw: "quay.io/nginx/nginx-unprivileged:1.27", Registry: "quay.io", Namespace: "nginx", Name: "nginx-unprivileged", Tag: "1.27"}
+	digest, err := client.GetDigest(context.Background(), image)
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:127", digest)
+
+	image.Tag = "0.1"
+	_, err = client.GetDigest(context.Background(), image)
+	assert.ErrorContains(t, err, "quay.io did not return the digest")
+
+	_, err = newTestClient(endpoint).GetDigest(context.Background(), image)
+	assert.ErrorContains(t, err, "received non-OK status code from Quay (401)")
+}
+
+// TestGetTags_UnsupportedRegistry tests the case where the image registry is not quay.io
+func TestGetTags_UnsupportedRegistry(t *testing.T) {
+	tags, err := NewClient().GetTags(context.Background(), types.Image{Registry: "docker.io", Namespace: "library", Name: "nginx"})
+	assert.Nil(t, tags)
+	assert.ErrorContains(t, err, "unsupported url for Quay")
+}
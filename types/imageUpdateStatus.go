@@ -1,13 +1,43 @@
 package types
 
+// CheckStatus classifies the outcome of the update check of a container
+type CheckStatus string
+
+const (
+	StatusUpdateAvailable     CheckStatus = "update_available"     // A newer version is available
+	StatusNewDigest           CheckStatus = "new_digest"           // A newer image is published under the same tag
+	StatusUpToDate            CheckStatus = "up_to_date"           // The container runs the latest version
+	StatusUnsupportedRegistry CheckStatus = "unsupported_registry" // No client can query the image registry
+	StatusInvalidImage        CheckStatus = "invalid_image"        // The image reference cannot be parsed
+	StatusNonSemverTag        CheckStatus = "non_semver_tag"       // The image tag is not a semantic version
+	StatusInvalidLabel        CheckStatus = "invalid_label"        // The update policy labels of the container are invalid
+	StatusFetchError          CheckStatus = "fetch_error"          // The registry tags could not be fetched
+	StatusCompareError        CheckStatus = "compare_error"        // The tags could not be compared
+	StatusSkipped             CheckStatus = "skipped"              // The container was excluded by a filter rule
+	StatusDisabled            CheckStatus = "disabled"             // The container opted out of checks with the chuck.enable=false label
+)
+
+// Skipped reports whether the container was left out of the check, by a filter rule or its labels
+func (s CheckStatus) Skipped() bool {
+	return s == StatusSkipped || s == StatusDisabled
+}
+
 // UpdateStatus represents the update status for a single container image
 type ImageUpdateStatus struct {
-	ContainerID        string `json:"containerId,omitempty" yaml:"containerId" csv:"container_id"`
-	ContainerName      string `json:"containerName,omitempty" yaml:"containerName" csv:"container_name"`
-	Image              Image  `json:"image" yaml:"image" csv:"image"`
-	OriginalTag        string `json:"originalTag,omitempty" yaml:"originalTag" csv:"original_tag"`
-	LatestAvailableTag string `json:"latestAvailableTag,omitempty" yaml:"latestAvailable_tag" csv:"latest_available_tag"`
-	UpdateAvailable    bool   `json:"updateAvailable,omitempty" yaml:"updateAvailable" csv:"update_available"`
-	StatusMessage      string `json:"statusMessage,omitempty" yaml:"statusMessage" csv:"status_message"`
-	Error              string `json:"error,omitempty" yaml:"error" csv:"error"`
+	ContainerID        string      `json:"containerId,omitempty" yaml:"containerId" csv:"container_id"`
+	ContainerName      string      `json:"containerName,omitempty" yaml:"containerName" csv:"container_name"`
+	Image              Image       `json:"image" yaml:"image" csv:"image"`
+	OriginalTag        string      `json:"originalTag,omitempty" yaml:"originalTag" csv:"original_tag"`
+	LatestAvailableTag string      `json:"latestAvailableTag,omitempty" yaml:"latestAvailable_tag" csv:"latest_available_tag"`
+	LatestPatchTag     string      `json:"latestPatchTag,omitempty" yaml:"latestPatchTag" csv:"latest_patch_tag"`
+	LatestMinorTag     string      `json:"latestMinorTag,omitempty" yaml:"latestMinorTag" csv:"latest_minor_tag"`
+	LatestMajorTag     string      `json:"latestMajorTag,omitempty" yaml:"latestMajorTag" csv:"latest_major_tag"`
+	CurrentTagModified string      `json:"currentTagModified,omitempty" yaml:"currentTagModified" csv:"current_tag_modified"` // Last push of the running tag (RFC 3339), when the registry reports it
+	LatestTagModified  string      `json:"latestTagModified,omitempty" yaml:"latestTagModified" csv:"latest_tag_modified"`    // Last push of the suggested tag (RFC 3339), when the registry reports it
+	CurrentDigest      string      `json:"currentDigest,omitempty" yaml:"currentDigest" csv:"current_digest"`
+	LatestDigest       string      `json:"latestDigest,omitempty" yaml:"latestDigest" csv:"latest_digest"`
+	UpdateAvailable    bool        `json:"updateAvailable,omitempty" yaml:"updateAvailable" csv:"update_available"`
+	Status             CheckStatus `json:"status,omitempty" yaml:"status" csv:"status"`
+	StatusMessage      string      `json:"statusMessage,omitempty" yaml:"statusMessage" csv:"status_message"`
+	Error              string      `json:"error,omitempty" yaml:"error" csv:"error"`
 }
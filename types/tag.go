@@ -0,0 +1,10 @@
+package types
+
+import "time"
+
+// TagInfo describes a tag along with the metadata some registries return when listing tags
+type TagInfo struct {
+	Name         string    `json:"name"`
+	LastModified time.Time `json:"lastModified,omitzero"` // Last time the tag was pushed, zero when unknown
+	Digest       string    `json:"digest,omitempty"`      // Digest of the manifest referenced by the tag
+}